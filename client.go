@@ -2,20 +2,30 @@ package box
 
 import (
 	"context"
+	"net/http"
 	"net/url"
+	"sync"
 
 	"github.com/gildas/go-logger"
 )
 
 // Client is the Box Client
 type Client struct {
-	Api         *url.URL       `json:"api"`
-	Proxy       *url.URL       `json:"proxy"`
-	Auth        *Auth          `json:"-"`
-	Files       *Files         `json:"-"`
-	Folders     *Folders       `json:"-"`
-	SharedLinks *SharedLinks   `json:"-"`
-	Logger      *logger.Logger `json:"-"`
+	Api             *url.URL         `json:"api"`
+	UploadApi       *url.URL         `json:"upload_api"` // The API used to upload content, Box.com uses a different host for uploads
+	Proxy           *url.URL         `json:"proxy"`
+	CaseSensitive   bool             `json:"case_sensitive"` // Tells if item names are compared case sensitively when finding items by name or path
+	Auth            *Auth            `json:"-"`
+	Files           *Files           `json:"-"`
+	FileVersions    *FileVersions    `json:"-"`
+	Folders         *Folders         `json:"-"`
+	SharedLinks     *SharedLinks     `json:"-"`
+	Representations *Representations `json:"-"`
+	Paths           *Paths           `json:"-"`
+	ZipDownloads    *ZipDownloads    `json:"-"`
+	Logger          *logger.Logger   `json:"-"`
+	httpclient      *http.Client
+	httpclientOnce  sync.Once
 }
 
 // NewClient instantiates a new Client
@@ -27,10 +37,15 @@ func NewClient(ctx context.Context) *Client {
 	}
 	client.Logger = log.Child("box", "box")
 	client.Api = &url.URL{Scheme: "https", Host: "api.box.com", Path: "/2.0/"}
+	client.UploadApi = &url.URL{Scheme: "https", Host: "upload.box.com", Path: "/api/2.0/"}
 	client.Auth = &Auth{client, client.moduleApi("/oauth2/token/"), TokenFromContext(ctx)}
 	client.Files = &Files{client, client.moduleApi("files/")}
+	client.FileVersions = &FileVersions{client, client.moduleApi("files/")}
 	client.Folders = &Folders{client, client.moduleApi("folders/")}
 	client.SharedLinks = &SharedLinks{client, client.moduleApi("files/")}
+	client.Representations = &Representations{client, client.moduleApi("files/")}
+	client.Paths = &Paths{Client: client}
+	client.ZipDownloads = &ZipDownloads{client, client.moduleApi("zip_downloads")}
 	return client
 }
 
@@ -1,26 +1,160 @@
 package box
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-request"
 )
 
 // Download download the content of a file (by its FileEntry)
-func (module *Files) Download(ctx context.Context, entry *FileEntry) (*request.Content, error) {
-	// query: version=string to get a specific version
+//
+// If the file is not ready yet, Box.com answers with 202 Accepted and Download waits
+// as instructed by the Retry-After header before trying again, until the context is done.
+//
+// If no options are given, the current version of the whole file is downloaded, as Download(ctx, entry) always did.
+// Only the first options are used.
+//
+// options.Version allows to download a previous version of the file (e.g.: from entry.FileVersion or a version listing),
+// options.Offset and options.Length allow to download only a part of the file.
+//
+// If options.VerifyChecksum is true, the downloaded data is verified against entry.Checksum
+// and ChecksumMismatch is returned if they differ.
+func (module *Files) Download(ctx context.Context, entry *FileEntry, options ...*DownloadOptions) (*request.Content, error) {
 	if entry == nil || len(entry.ID) == 0 {
 		return nil, errors.ArgumentMissing.With("entry")
 	}
+	downloadOptions := &DownloadOptions{}
+	if len(options) > 0 && options[0] != nil {
+		downloadOptions = options[0]
+	}
+	if err := downloadOptions.validate(entry); err != nil {
+		return nil, err
+	}
 	if !module.Client.IsAuthenticated() {
 		return nil, errors.Unauthorized.WithStack()
 	}
 
-	downloadURL, _ := module.api.Parse(entry.ID + "/content")
-	return module.Client.sendRequest(ctx, &request.Options{
-		URL: downloadURL,
-	}, nil)
-	// TODO: if statuscode is 202 accepted, res should have a header "Retry-After" to tell when we can download the file
-	// Seems like HTTP 200 happens on small files
+	buffer := bytes.Buffer{}
+	_, headers, err := module.download(ctx, entry, &buffer, downloadOptions)
+	if err != nil {
+		return nil, err
+	}
+	return request.ContentWithData(buffer.Bytes(), contentType(entry, headers), headers), nil
+}
+
+// DownloadTo downloads the content of a file (by its FileEntry) and streams it to the given writer
+//
+// Unlike Download, the content is not kept in memory, which is better for large files.
+//
+// To resume a partial download, set options.Offset to the number of bytes already downloaded.
+//
+// DownloadTo returns the number of bytes written to the writer.
+func (module *Files) DownloadTo(ctx context.Context, entry *FileEntry, writer io.Writer, options *DownloadOptions) (int64, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return 0, errors.ArgumentMissing.With("entry")
+	}
+	if writer == nil {
+		return 0, errors.ArgumentMissing.With("writer")
+	}
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	if err := options.validate(entry); err != nil {
+		return 0, err
+	}
+	if !module.Client.IsAuthenticated() {
+		return 0, errors.Unauthorized.WithStack()
+	}
+	written, _, err := module.download(ctx, entry, writer, options)
+	return written, err
+}
+
+// download streams the content of a file to the given writer
+func (module *Files) download(ctx context.Context, entry *FileEntry, writer io.Writer, options *DownloadOptions) (_ int64, _ http.Header, err error) {
+	res, err := module.Client.sendContentRequest(ctx, options.contentURL(module.api, entry), options.headers(), true)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	if options.IsRange() && res.StatusCode != http.StatusPartialContent {
+		// The server sent the whole file, skip what was not requested
+		if _, err := io.CopyN(io.Discard, res.Body, options.Offset); err != nil {
+			return 0, nil, errors.WithStack(err)
+		}
+	}
+
+	var reader io.Reader = res.Body
+	if options.Length > 0 {
+		reader = io.LimitReader(res.Body, options.Length)
+	}
+
+	hasher := sha1.New()
+	destination := writer
+	if options.VerifyChecksum {
+		destination = io.MultiWriter(destination, hasher)
+	}
+	size := res.ContentLength
+	if options.Length > 0 && (size < 0 || options.Length < size) {
+		size = options.Length
+	}
+	if tracker := newProgressTracker(options.ProgressWriter, options.OnProgress, size); tracker != nil {
+		defer func() { tracker.finish(err) }()
+		destination = io.MultiWriter(destination, tracker)
+	}
+
+	written, err := io.Copy(destination, reader)
+	if err != nil {
+		return written, nil, errors.WithStack(err)
+	}
+	if options.VerifyChecksum {
+		if actual := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(actual, entry.Checksum) {
+			return written, nil, ChecksumMismatch.With(entry.ID, entry.Checksum)
+		}
+	}
+	return written, res.Header, nil
+}
+
+// validate validates these options for the given FileEntry
+func (options DownloadOptions) validate(entry *FileEntry) error {
+	if options.Offset < 0 {
+		return errors.ArgumentInvalid.With("offset", options.Offset)
+	}
+	if options.Length < 0 {
+		return errors.ArgumentInvalid.With("length", options.Length)
+	}
+	if options.VerifyChecksum {
+		if len(entry.Checksum) == 0 {
+			return errors.ArgumentMissing.With("sha1")
+		}
+		if options.IsRange() {
+			return errors.ArgumentInvalid.With("offset", options.Offset)
+		}
+		if len(options.Version) > 0 && options.Version != entry.FileVersion.ID {
+			return errors.ArgumentInvalid.With("version", options.Version)
+		}
+	}
+	return nil
+}
+
+// contentType gives the MIME type of the downloaded content
+//
+// If the server does not give a meaningful one, it is computed from the name of the FileEntry
+func contentType(entry *FileEntry, headers http.Header) string {
+	if value := headers.Get("Content-Type"); len(value) > 0 && value != "application/octet-stream" {
+		return value
+	}
+	if value := mime.TypeByExtension(filepath.Ext(entry.Name)); len(value) > 0 {
+		return value
+	}
+	return "application/octet-stream"
 }
@@ -0,0 +1,218 @@
+package box
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// DownloadDirectoryOptions contains the options for downloading a folder tree
+type DownloadDirectoryOptions struct {
+	// Workers is the number of files downloaded at the same time (default: DefaultDownloadWorkers)
+	Workers int
+	// Include are the glob patterns (see path.Match) of the files to download, all files are downloaded if empty
+	Include []string
+	// Exclude are the glob patterns (see path.Match) of the files and folders not to download
+	Exclude []string
+}
+
+// DownloadReport tells what happened to each file of a downloaded folder tree
+type DownloadReport struct {
+	Files []DownloadResult `json:"files"`
+}
+
+// DownloadResult tells what happened to a file of a downloaded folder tree
+type DownloadResult struct {
+	// Path is the path of the file relative to the downloaded folder, with "/" as separator
+	Path string `json:"path"`
+	// Status is "downloaded", "resumed", "skipped" or "failed"
+	Status string `json:"status"`
+	// File is the FileEntry on Box.com
+	File *FileEntry `json:"file"`
+	// Error is the reason of the failure
+	Error error `json:"-"`
+}
+
+// PartialDownloadSuffix is added to the name of the local files while they are downloaded
+const PartialDownloadSuffix = ".part"
+
+// DownloadDirectory downloads all the files of a folder tree into a local directory
+//
+// The structure of the folder is preserved and the modification time of the local files is set to the content_modified_at of the files.
+// Local files whose SHA1 already matches are skipped. The files are downloaded to a file with PartialDownloadSuffix first,
+// which is resumed if it exists from an interrupted download. The SHA1 of each downloaded file is verified
+// before it is renamed, a resumed file that does not match is downloaded again from the start.
+//
+// Patterns are matched against the path of the items relative to folder (e.g.: "reports/*.pdf")
+// and, for patterns without "/", against their name (e.g.: "*.tmp").
+//
+// The report tells what happened to each file, files that could not be downloaded do not stop the download.
+func (module *Files) DownloadDirectory(ctx context.Context, folder *FolderEntry, directory string, options *DownloadDirectoryOptions) (*DownloadReport, error) {
+	if folder == nil || len(folder.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("folder")
+	}
+	if len(directory) == 0 {
+		return nil, errors.ArgumentMissing.With("directory")
+	}
+	if options == nil {
+		options = &DownloadDirectoryOptions{}
+	}
+	for _, pattern := range append(append([]string{}, options.Include...), options.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.ArgumentInvalid.With("pattern", pattern)
+		}
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultDownloadWorkers
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	report := &DownloadReport{Files: []DownloadResult{}}
+	err := module.Client.Folders.Walk(ctx, folder, func(itemPath string, item *Item, err error) error {
+		if err != nil {
+			return err
+		}
+		relative := strings.TrimPrefix(itemPath, "/")
+		switch {
+		case item.Folder != nil:
+			if len(relative) > 0 && matchesAny(options.Exclude, relative) {
+				return fs.SkipDir
+			}
+			if err := os.MkdirAll(filepath.Join(directory, filepath.FromSlash(relative)), 0755); err != nil {
+				return errors.WithStack(err)
+			}
+		case item.File != nil:
+			if matchesAny(options.Exclude, relative) || (len(options.Include) > 0 && !matchesAny(options.Include, relative)) {
+				return nil
+			}
+			report.Files = append(report.Files, DownloadResult{Path: relative, File: item.File})
+		}
+		return nil
+	}, &WalkOptions{Fields: []string{"type", "id", "name", "size", "sha1", "etag", "content_modified_at"}})
+	if err != nil {
+		return report, err
+	}
+
+	jobs := make(chan int)
+	var wait sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range jobs {
+				result := &report.Files[index]
+				result.Status, result.Error = module.downloadLocalFile(ctx, result.File, filepath.Join(directory, filepath.FromSlash(result.Path)))
+			}
+		}()
+	}
+	for index := range report.Files {
+		jobs <- index
+	}
+	close(jobs)
+	wait.Wait()
+	return report, nil
+}
+
+// Failed gives the files that could not be downloaded
+func (report DownloadReport) Failed() []DownloadResult {
+	failed := []DownloadResult{}
+	for _, result := range report.Files {
+		if result.Status == "failed" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// downloadLocalFile downloads a file to the given local path
+func (module *Files) downloadLocalFile(ctx context.Context, entry *FileEntry, localPath string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "failed", errors.WithStack(err)
+	}
+	if info, err := os.Stat(localPath); err == nil && info.Mode().IsRegular() && info.Size() == entry.Size {
+		if checksum, err := localChecksum(localPath); err == nil && strings.EqualFold(checksum, entry.Checksum) {
+			return "skipped", nil
+		}
+	}
+
+	partialPath := localPath + PartialDownloadSuffix
+	status := "downloaded"
+	offset := int64(0)
+	if info, err := os.Stat(partialPath); err == nil && info.Mode().IsRegular() && info.Size() > 0 && info.Size() <= entry.Size {
+		status, offset = "resumed", info.Size()
+	}
+	for {
+		if err := module.downloadPartialFile(ctx, entry, partialPath, offset); err != nil {
+			return "failed", err
+		}
+		if len(entry.Checksum) == 0 {
+			break
+		}
+		checksum, err := localChecksum(partialPath)
+		if err != nil {
+			return "failed", err
+		}
+		if strings.EqualFold(checksum, entry.Checksum) {
+			break
+		}
+		_ = os.Remove(partialPath)
+		if offset == 0 {
+			return "failed", ChecksumMismatch.With(entry.ID, entry.Checksum)
+		}
+		// The partial file may come from another version of the file, it is downloaded again from the start
+		status, offset = "downloaded", 0
+	}
+
+	if err := os.Rename(partialPath, localPath); err != nil {
+		return "failed", errors.WithStack(err)
+	}
+	if !entry.ContentModifiedAt.IsZero() {
+		if err := os.Chtimes(localPath, entry.ContentModifiedAt, entry.ContentModifiedAt); err != nil {
+			return "failed", errors.WithStack(err)
+		}
+	}
+	return status, nil
+}
+
+// downloadPartialFile downloads a file to a partial local file, starting at the given offset
+func (module *Files) downloadPartialFile(ctx context.Context, entry *FileEntry, partialPath string, offset int64) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(partialPath, flags, 0644)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+	if offset < entry.Size || entry.Size == 0 {
+		if _, err := module.DownloadTo(ctx, entry, file, &DownloadOptions{Offset: offset}); err != nil {
+			return err
+		}
+	}
+	return errors.WithStack(file.Close())
+}
+
+// matchesAny tells if the given relative path or its name matches any of the glob patterns
+func matchesAny(patterns []string, relative string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, relative); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(relative)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type DownloadDirectorySuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server     *httptest.Server
+	ServerURL  *url.URL
+	ModifiedAt time.Time
+	Mutex      sync.Mutex
+	Ranges     []string
+}
+
+func TestDownloadDirectorySuite(t *testing.T) {
+	suite.Run(t, new(DownloadDirectorySuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *DownloadDirectorySuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.ModifiedAt = time.Date(2021, time.February, 3, 4, 5, 6, 0, time.UTC)
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *DownloadDirectorySuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *DownloadDirectorySuite) BeforeTest(suiteName, testName string) {
+	suite.Mutex.Lock()
+	suite.Ranges = []string{}
+	suite.Mutex.Unlock()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *DownloadDirectorySuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+// downloadTreeFolders are the folders served by the test server, downloadTreeFiles are the contents of the files
+var (
+	downloadTreeFolders = map[string][][2]string{
+		"0": {{"folder", "1"}, {"file", "10"}, {"file", "11"}},
+		"1": {{"file", "12"}, {"folder", "2"}},
+		"2": {{"file", "13"}},
+		"3": {{"file", "14"}},
+	}
+	downloadTreeNames = map[string]string{"1": "docs", "2": "private", "10": "a.txt", "11": "skip.tmp", "12": "b.pdf", "13": "c.txt", "14": "corrupted.txt"}
+	downloadTreeFiles = map[string][]byte{
+		"10": []byte("Hello, World!"),
+		"11": []byte("temporary"),
+		"12": bytes.Repeat([]byte("%PDF"), 100),
+		"13": []byte("private"),
+		"14": []byte("corrupted"),
+	}
+)
+
+func (suite *DownloadDirectorySuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/2.0/"), "/")
+		switch {
+		case len(segments) == 3 && segments[0] == "folders" && segments[2] == "items":
+			entries := []map[string]interface{}{}
+			for _, child := range downloadTreeFolders[segments[1]] {
+				entry := map[string]interface{}{"type": child[0], "id": child[1], "name": downloadTreeNames[child[1]]}
+				if child[0] == "file" {
+					checksum := sha1.Sum(downloadTreeFiles[child[1]])
+					entry["size"] = len(downloadTreeFiles[child[1]])
+					entry["sha1"] = hex.EncodeToString(checksum[:])
+					entry["content_modified_at"] = suite.ModifiedAt.Format(time.RFC3339)
+					if child[1] == "14" {
+						entry["sha1"] = "0000000000000000000000000000000000000000"
+					}
+				}
+				entries = append(entries, entry)
+			}
+			res.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(res).Encode(map[string]interface{}{"entries": entries, "limit": 1000, "next_marker": ""})
+		case len(segments) == 3 && segments[0] == "files" && segments[2] == "content":
+			data, found := downloadTreeFiles[segments[1]]
+			if !found {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if value := req.Header.Get("Range"); len(value) > 0 {
+				suite.Mutex.Lock()
+				suite.Ranges = append(suite.Ranges, segments[1]+":"+value)
+				suite.Mutex.Unlock()
+			}
+			http.ServeContent(res, req, downloadTreeNames[segments[1]], time.Now(), bytes.NewReader(data))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *DownloadDirectorySuite) Statuses(report *DownloadReport) map[string]string {
+	statuses := map[string]string{}
+	for _, result := range report.Files {
+		statuses[result.Path] = result.Status
+	}
+	return statuses
+}
+
+func (suite *DownloadDirectorySuite) TestCanDownloadDirectory() {
+	directory := suite.T().TempDir()
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "0"}, directory, &DownloadDirectoryOptions{
+		Exclude: []string{"*.tmp", "docs/private"},
+	})
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"a.txt": "downloaded", "docs/b.pdf": "downloaded"}, suite.Statuses(report))
+	suite.Assert().Empty(report.Failed())
+
+	data, err := os.ReadFile(filepath.Join(directory, "docs", "b.pdf"))
+	suite.Require().Nilf(err, "Failed reading downloaded file. Error: %s", err)
+	suite.Assert().Equal(downloadTreeFiles["12"], data)
+	info, err := os.Stat(filepath.Join(directory, "a.txt"))
+	suite.Require().Nilf(err, "Failed reading downloaded file. Error: %s", err)
+	suite.Assert().True(suite.ModifiedAt.Equal(info.ModTime()), "Modification time should be %s, got %s", suite.ModifiedAt, info.ModTime())
+	suite.Assert().NoFileExists(filepath.Join(directory, "a.txt"+PartialDownloadSuffix))
+	suite.Assert().NoDirExists(filepath.Join(directory, "docs", "private"))
+}
+
+func (suite *DownloadDirectorySuite) TestCanDownloadDirectoryWithInclude() {
+	directory := suite.T().TempDir()
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "0"}, directory, &DownloadDirectoryOptions{
+		Include: []string{"docs/*/*.txt", "*.pdf"},
+	})
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"docs/b.pdf": "downloaded", "docs/private/c.txt": "downloaded"}, suite.Statuses(report))
+}
+
+func (suite *DownloadDirectorySuite) TestShouldSkipDownloadedFiles() {
+	directory := suite.T().TempDir()
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "1"}, directory, nil)
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+
+	report, err := client.Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "1"}, directory, nil)
+	suite.Require().Nilf(err, "Failed downloading directory again. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"b.pdf": "skipped", "private/c.txt": "skipped"}, suite.Statuses(report))
+}
+
+func (suite *DownloadDirectorySuite) TestCanResumePartialDownload() {
+	directory := suite.T().TempDir()
+	err := os.WriteFile(filepath.Join(directory, "a.txt"+PartialDownloadSuffix), downloadTreeFiles["10"][:5], 0644)
+	suite.Require().Nilf(err, "Failed writing partial file. Error: %s", err)
+
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "0"}, directory, &DownloadDirectoryOptions{Include: []string{"a.txt"}})
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"a.txt": "resumed"}, suite.Statuses(report))
+	suite.Assert().Equal([]string{"10:bytes=5-"}, suite.Ranges)
+	data, err := os.ReadFile(filepath.Join(directory, "a.txt"))
+	suite.Require().Nilf(err, "Failed reading downloaded file. Error: %s", err)
+	suite.Assert().Equal(downloadTreeFiles["10"], data)
+}
+
+func (suite *DownloadDirectorySuite) TestShouldRestartCorruptedPartialDownload() {
+	directory := suite.T().TempDir()
+	err := os.WriteFile(filepath.Join(directory, "a.txt"+PartialDownloadSuffix), []byte("Bye, "), 0644)
+	suite.Require().Nilf(err, "Failed writing partial file. Error: %s", err)
+
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "0"}, directory, &DownloadDirectoryOptions{Include: []string{"a.txt"}})
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"a.txt": "downloaded"}, suite.Statuses(report))
+	data, err := os.ReadFile(filepath.Join(directory, "a.txt"))
+	suite.Require().Nilf(err, "Failed reading downloaded file. Error: %s", err)
+	suite.Assert().Equal(downloadTreeFiles["10"], data)
+}
+
+func (suite *DownloadDirectorySuite) TestShouldFailDownloadingWithChecksumMismatch() {
+	directory := suite.T().TempDir()
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "3"}, directory, nil)
+	suite.Require().Nilf(err, "Failed downloading directory. Error: %s", err)
+	failed := report.Failed()
+	suite.Require().Len(failed, 1)
+	suite.Assert().Truef(errors.Is(failed[0].Error, ChecksumMismatch), "Error should be a Checksum Mismatch Error. Error: %v", failed[0].Error)
+	suite.Assert().NoFileExists(filepath.Join(directory, "corrupted.txt"))
+	suite.Assert().NoFileExists(filepath.Join(directory, "corrupted.txt"+PartialDownloadSuffix))
+}
+
+func (suite *DownloadDirectorySuite) TestShouldFailDownloadingDirectoryWithInvalidPattern() {
+	_, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.DownloadDirectory(context.Background(), &FolderEntry{ID: "0"}, suite.T().TempDir(), &DownloadDirectoryOptions{Include: []string{"[a-"}})
+	suite.Require().NotNil(err, "Should have failed downloading directory")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an Argument Invalid Error. Error: %v", err)
+}
@@ -0,0 +1,187 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// DefaultDownloadWorkers is the default number of concurrent range requests used by Files.DownloadAt
+const DefaultDownloadWorkers = 4
+
+// DefaultDownloadChunkSize is the default size of the ranges requested by Files.DownloadAt
+const DefaultDownloadChunkSize = int64(8 * 1024 * 1024)
+
+// DefaultDownloadChunkAttempts is the number of times a range is requested before giving up
+const DefaultDownloadChunkAttempts = 3
+
+// DownloadAt downloads the content of a file (by its FileEntry) with concurrent range requests
+//
+// The file is split in ranges of options.ChunkSize bytes according to entry.Size,
+// which are fetched by options.Workers workers and written at their position in the given writer (e.g.: an *os.File).
+//
+// If options.VerifyChecksum is true, the ranges are hashed in order as they complete and the final SHA1
+// is verified against entry.Checksum. At most 2 * options.Workers ranges are kept in memory.
+//
+// DownloadAt returns the number of bytes written to the writer.
+func (module *Files) DownloadAt(ctx context.Context, entry *FileEntry, writer io.WriterAt, options *DownloadOptions) (_ int64, err error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return 0, errors.ArgumentMissing.With("entry")
+	}
+	if writer == nil {
+		return 0, errors.ArgumentMissing.With("writer")
+	}
+	if options == nil {
+		options = &DownloadOptions{}
+	}
+	if err := options.validate(entry); err != nil {
+		return 0, err
+	}
+	if entry.Size < 0 || options.Offset > entry.Size {
+		return 0, errors.ArgumentInvalid.With("size", entry.Size)
+	}
+	if !module.Client.IsAuthenticated() {
+		return 0, errors.Unauthorized.WithStack()
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultDownloadWorkers
+	}
+	chunkSize := options.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultDownloadChunkSize
+	}
+	start, end := options.Offset, entry.Size
+	if options.Length > 0 && start+options.Length < end {
+		end = start + options.Length
+	}
+	chunks := int((end - start + chunkSize - 1) / chunkSize)
+
+	progress := newProgressTracker(options.ProgressWriter, options.OnProgress, end-start)
+	if progress != nil {
+		defer func() { progress.finish(err) }()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mutex    sync.Mutex
+		firstErr error
+		written  int64
+		hashed   int
+		pending  = map[int][]byte{}
+		hasher   = sha1.New()
+		inflight = make(chan struct{}, 2*workers)
+		jobs     = make(chan int)
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	complete := func(index int, data []byte) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		written += int64(len(data))
+		if !options.VerifyChecksum {
+			<-inflight
+			return
+		}
+		// Hash the completed ranges in order, releasing their memory as we go
+		pending[index] = data
+		for chunk, ok := pending[hashed]; ok; chunk, ok = pending[hashed] {
+			_, _ = hasher.Write(chunk)
+			delete(pending, hashed)
+			hashed++
+			<-inflight
+		}
+	}
+
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				offset := start + int64(index)*chunkSize
+				length := chunkSize
+				if offset+length > end {
+					length = end - offset
+				}
+				data, err := module.downloadChunk(ctx, entry, options.Version, offset, length)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				if _, err := writer.WriteAt(data, offset); err != nil {
+					fail(errors.WithStack(err))
+					continue
+				}
+				if progress != nil {
+					_, _ = progress.Write(data)
+				}
+				complete(index, data)
+			}
+		}()
+	}
+
+dispatch:
+	for index := 0; index < chunks; index++ {
+		select {
+		case inflight <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return written, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return written, errors.WithStack(err)
+	}
+	if options.VerifyChecksum {
+		if actual := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(actual, entry.Checksum) {
+			return written, ChecksumMismatch.With(entry.ID, entry.Checksum)
+		}
+	}
+	return written, nil
+}
+
+// downloadChunk downloads a range of a file in memory
+func (module *Files) downloadChunk(ctx context.Context, entry *FileEntry, version string, offset, length int64) (data []byte, err error) {
+	log := module.Client.Logger.Child(nil, "download", "file", entry.ID, "offset", offset, "length", length)
+	for attempt := 1; attempt <= DefaultDownloadChunkAttempts; attempt++ {
+		buffer := bytes.NewBuffer(make([]byte, 0, length))
+		_, _, err = module.download(ctx, entry, buffer, &DownloadOptions{Version: version, Offset: offset, Length: length})
+		if err == nil && int64(buffer.Len()) != length {
+			err = errors.HTTPStatusRequestedRangeNotSatisfiable.Wrap(io.ErrUnexpectedEOF)
+		}
+		if err == nil {
+			return buffer.Bytes(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		log.Warnf("Failed to download range (attempt %d/%d): %s", attempt, DefaultDownloadChunkAttempts, err.Error())
+	}
+	return nil, err
+}
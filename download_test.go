@@ -0,0 +1,258 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type DownloadSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Data      []byte
+	Entry     *FileEntry
+	Requests  atomic.Int32
+}
+
+func TestDownloadSuite(t *testing.T) {
+	suite.Run(t, new(DownloadSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *DownloadSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Data = bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 100)
+	checksum := sha1.Sum(suite.Data)
+	suite.Entry = &FileEntry{
+		Type:        "file",
+		ID:          "1234",
+		Name:        "data.txt",
+		Size:        int64(len(suite.Data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		FileVersion: FileVersion{Type: "file_version", ID: "5678", Checksum: hex.EncodeToString(checksum[:])},
+	}
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *DownloadSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *DownloadSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *DownloadSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *DownloadSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		suite.Requests.Add(1)
+		if req.Method != http.MethodGet || req.URL.Path != "/2.0/files/1234/content" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if version := req.URL.Query().Get("version"); len(version) > 0 && version != suite.Entry.FileVersion.ID {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(res, req, suite.Entry.Name, time.Now(), bytes.NewReader(suite.Data))
+	}))
+}
+
+func (suite *DownloadSuite) TestCanDownload() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	content, err := client.Files.Download(context.Background(), suite.Entry, &DownloadOptions{Version: "5678", VerifyChecksum: true})
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Assert().Equal(suite.Data, content.Data)
+	suite.Assert().Equal("text/plain; charset=utf-8", content.Type)
+}
+
+func (suite *DownloadSuite) TestCanDownloadWithoutOptions() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	content, err := client.Files.Download(context.Background(), suite.Entry)
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Assert().Equal(suite.Data, content.Data)
+}
+
+func (suite *DownloadSuite) TestCanReportDownloadProgress() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	reports := []Progress{}
+	_, err := client.Files.DownloadTo(context.Background(), suite.Entry, io.Discard, &DownloadOptions{
+		Offset:     1000,
+		OnProgress: func(progress Progress) { reports = append(reports, progress) },
+	})
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	last := reports[len(reports)-1]
+	suite.Assert().True(last.Done, "The last report should be done")
+	suite.Assert().Equal(int64(len(suite.Data)-1000), last.Transferred)
+	suite.Assert().Equal(int64(len(suite.Data)-1000), last.Total)
+	suite.Assert().Greater(last.Rate, float64(0))
+}
+
+func (suite *DownloadSuite) TestCanDownloadRange() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	content, err := client.Files.Download(context.Background(), suite.Entry, &DownloadOptions{Offset: 10, Length: 26})
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Assert().Equal("abcdefghijklmnopqrstuvwxyz", string(content.Data))
+}
+
+func (suite *DownloadSuite) TestCanResumeDownloadTo() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	original := append([]byte{}, suite.Data...)
+	writer := bytes.NewBuffer(append([]byte{}, suite.Data[:1000]...))
+	written, err := client.Files.DownloadTo(context.Background(), suite.Entry, writer, &DownloadOptions{Offset: 1000})
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Assert().Equal(int64(len(original)-1000), written)
+	suite.Assert().Equal(original, writer.Bytes())
+	suite.Assert().Equal(original, suite.Data, "The test data should not have changed")
+}
+
+func (suite *DownloadSuite) TestCanDownloadAt() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	path := filepath.Join(suite.T().TempDir(), suite.Entry.Name)
+	writer, err := os.Create(path)
+	suite.Require().Nilf(err, "Failed creating local file. Error: %s", err)
+	defer writer.Close()
+
+	progress := bytes.Buffer{}
+	reports := []Progress{}
+	written, err := client.Files.DownloadAt(context.Background(), suite.Entry, writer, &DownloadOptions{
+		Workers:        3,
+		ChunkSize:      100,
+		VerifyChecksum: true,
+		ProgressWriter: &progress,
+		OnProgress:     func(progress Progress) { reports = append(reports, progress) },
+	})
+	suite.Require().Nilf(err, "Failed downloading. Error: %s", err)
+	suite.Assert().Equal(int64(len(suite.Data)), written)
+	suite.Assert().Equal(len(suite.Data), progress.Len())
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	last := reports[len(reports)-1]
+	suite.Assert().True(last.Done, "The last report should be done")
+	suite.Assert().Equal(int64(len(suite.Data)), last.Transferred)
+	suite.Assert().Equal(int64(len(suite.Data)), last.Total)
+	suite.Assert().Equal(float64(100), last.Percent())
+	data, err := os.ReadFile(path)
+	suite.Require().Nilf(err, "Failed reading local file. Error: %s", err)
+	suite.Assert().Equal(suite.Data, data)
+}
+
+func (suite *DownloadSuite) TestShouldFailDownloadingAtWithChecksumMismatch() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	entry := *suite.Entry
+	entry.Checksum = "0000000000000000000000000000000000000000"
+	path := filepath.Join(suite.T().TempDir(), suite.Entry.Name)
+	writer, err := os.Create(path)
+	suite.Require().Nilf(err, "Failed creating local file. Error: %s", err)
+	defer writer.Close()
+
+	_, err = client.Files.DownloadAt(context.Background(), &entry, writer, &DownloadOptions{ChunkSize: 1000, VerifyChecksum: true})
+	suite.Require().NotNil(err, "Should have failed downloading")
+	suite.Assert().Truef(errors.Is(err, ChecksumMismatch), "Error should be a Checksum Mismatch Error. Error: %v", err)
+}
+
+func (suite *DownloadSuite) TestCanReadFileAt() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	file, err := client.Files.Open(context.Background(), suite.Entry, &OpenOptions{BlockSize: 100})
+	suite.Require().Nilf(err, "Failed opening file. Error: %s", err)
+	defer file.Close()
+	suite.Assert().Equal(int64(len(suite.Data)), file.Size())
+
+	data := make([]byte, 250)
+	read, err := file.ReadAt(data, 90)
+	suite.Require().Nilf(err, "Failed reading file. Error: %s", err)
+	suite.Assert().Equal(250, read)
+	suite.Assert().Equal(suite.Data[90:340], data)
+
+	read, err = file.ReadAt(data, int64(len(suite.Data)-10))
+	suite.Assert().Equal(io.EOF, err)
+	suite.Assert().Equal(10, read)
+	suite.Assert().Equal(suite.Data[len(suite.Data)-10:], data[:read])
+}
+
+func (suite *DownloadSuite) TestCanSeekAndReadFile() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	file, err := client.Files.Open(context.Background(), suite.Entry, &OpenOptions{BlockSize: 100})
+	suite.Require().Nilf(err, "Failed opening file. Error: %s", err)
+	defer file.Close()
+
+	offset, err := file.Seek(-36, io.SeekEnd)
+	suite.Require().Nilf(err, "Failed seeking file. Error: %s", err)
+	suite.Assert().Equal(int64(len(suite.Data)-36), offset)
+	footer, err := io.ReadAll(file)
+	suite.Require().Nilf(err, "Failed reading file. Error: %s", err)
+	suite.Assert().Equal("0123456789abcdefghijklmnopqrstuvwxyz", string(footer))
+}
+
+func (suite *DownloadSuite) TestFileShouldCacheBlocks() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	file, err := client.Files.Open(context.Background(), suite.Entry, &OpenOptions{BlockSize: 100, CacheBlocks: 2})
+	suite.Require().Nilf(err, "Failed opening file. Error: %s", err)
+	defer file.Close()
+
+	suite.Requests.Store(0)
+	data := make([]byte, 10)
+	for i := 0; i < 5; i++ {
+		_, err = file.ReadAt(data, int64(10*i))
+		suite.Require().Nilf(err, "Failed reading file. Error: %s", err)
+	}
+	suite.Assert().Equal(int32(1), suite.Requests.Load(), "Reads in the same block should use the cache")
+
+	for _, offset := range []int64{100, 200, 0} {
+		_, err = file.ReadAt(data, offset)
+		suite.Require().Nilf(err, "Failed reading file. Error: %s", err)
+	}
+	suite.Assert().Equal(int32(4), suite.Requests.Load(), "The first block should have been evicted")
+}
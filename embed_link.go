@@ -0,0 +1,58 @@
+package box
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// EmbedLink represents an expiring link to embed the preview of a file
+type EmbedLink struct {
+	URL       *url.URL
+	ExpiresAt time.Time
+}
+
+// IsExpired tells if this link has expired
+func (link EmbedLink) IsExpired() bool {
+	return time.Now().After(link.ExpiresAt)
+}
+
+// EmbedLink gets an expiring link to embed the preview of a file (e.g.: in an iframe)
+//
+// If the file cannot be previewed, RequestedPreviewUnavailable is returned.
+func (module *Files) EmbedLink(ctx context.Context, entry *FileEntry) (*EmbedLink, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	linkURL, _ := module.api.Parse(entry.ID)
+	result := struct {
+		Link *struct {
+			URL   *core.URL `json:"url"`
+			Token struct {
+				ExpiresIn int64 `json:"expires_in"`
+			} `json:"token"`
+		} `json:"expiring_embed_link"`
+	}{}
+	requestedAt := time.Now()
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL:        linkURL,
+		Parameters: map[string]string{"fields": "expiring_embed_link"},
+	}, &result); err != nil {
+		return nil, err
+	}
+	if result.Link == nil || result.Link.URL == nil {
+		return nil, errors.WithStack(RequestedPreviewUnavailable)
+	}
+	return &EmbedLink{
+		URL:       (*url.URL)(result.Link.URL),
+		ExpiresAt: requestedAt.Add(time.Duration(result.Link.Token.ExpiresIn) * time.Second),
+	}, nil
+}
@@ -2,6 +2,7 @@ package box
 
 import (
 	"encoding/json"
+	"net/http"
 	"net/url"
 
 	"github.com/gildas/go-core"
@@ -22,9 +23,38 @@ type RequestError struct {
 
 // ContextInfo gives some contextual information about the current error
 type ContextInfo struct {
-	// TODO: Find the best representation of this thing
-	// https://developer.box.com/reference is not clear if it is errors or conflicts
-	Errors []byte `json:"errors"`
+	// Errors contains the raw JSON of the errors, if any (e.g.: invalid parameters)
+	Errors json.RawMessage `json:"errors"`
+	// Conflicts contains the items that conflict with the request (e.g.: with ItemNameInUse)
+	Conflicts []PathEntry `json:"conflicts,omitempty"`
+}
+
+// UnmarshalJSON decodes JSON
+//
+// Box.com gives the conflicts either as an array or as a single object
+func (info *ContextInfo) UnmarshalJSON(payload []byte) (err error) {
+	var inner struct {
+		Errors    json.RawMessage `json:"errors"`
+		Conflicts json.RawMessage `json:"conflicts"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	*info = ContextInfo{}
+	if len(inner.Errors) > 0 && string(inner.Errors) != "null" {
+		info.Errors = inner.Errors
+	}
+	if len(inner.Conflicts) > 0 && string(inner.Conflicts) != "null" {
+		if inner.Conflicts[0] == '[' {
+			err = json.Unmarshal(inner.Conflicts, &info.Conflicts)
+		} else {
+			var conflict PathEntry
+			if err = json.Unmarshal(inner.Conflicts, &conflict); err == nil {
+				info.Conflicts = []PathEntry{conflict}
+			}
+		}
+	}
+	return errors.JSONUnmarshalError.Wrap(err)
 }
 
 // MarshalJSON marshals this into JSON
@@ -133,3 +163,9 @@ var (
 	InternalServerError                    = RequestError{Type: "error", ID: "internal_server_error", StatusCode: 500, Message: "Internal Server Error"}
 	UnavailableError                       = RequestError{Type: "error", ID: "unavailable", StatusCode: 503, Message: "Unavailable"}
 )
+
+// ChecksumMismatch is returned when the SHA1 of downloaded data does not match the one of its FileEntry
+var ChecksumMismatch = errors.NewSentinel(http.StatusUnprocessableEntity, "error.checksum.mismatch", "Checksum of %s does not match (expected: %v)")
+
+// ZipDownloadFailed is returned when Box.com could not complete the download of a zip archive
+var ZipDownloadFailed = errors.NewSentinel(http.StatusInternalServerError, "error.zipdownload.failed", "Zip download %s failed")
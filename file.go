@@ -0,0 +1,209 @@
+package box
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// DefaultBlockSize is the default size of the blocks read by a File
+const DefaultBlockSize = int64(64 * 1024)
+
+// DefaultCacheBlocks is the default number of blocks cached by a File
+const DefaultCacheBlocks = 16
+
+// File gives random access to the content of a Box.com file
+//
+// File implements io.ReaderAt, io.ReadSeeker and io.Closer, its content is read with HTTP Range requests
+// by blocks that are kept in a small LRU cache.
+// This allows reading parts of large files (zip central directories, Parquet footers, etc)
+// without downloading them entirely.
+//
+// ReadAt is safe for concurrent use, Read and Seek are not.
+type File struct {
+	Entry     *FileEntry
+	module    *Files
+	ctx       context.Context
+	version   string
+	size      int64
+	offset    int64
+	blockSize int64
+	capacity  int
+	blocks    map[int64]*list.Element
+	lru       *list.List
+	mutex     sync.Mutex
+	closed    bool
+}
+
+// OpenOptions contains the options for opening a File
+type OpenOptions struct {
+	// BlockSize is the size of the blocks requested from Box.com (default: DefaultBlockSize)
+	BlockSize int64
+	// CacheBlocks is the number of blocks kept in memory (default: DefaultCacheBlocks)
+	CacheBlocks int
+}
+
+type fileBlock struct {
+	index int64
+	data  []byte
+}
+
+// Open opens a File to read the content of the given FileEntry
+//
+// The given context is used for all the requests sent while reading the File.
+// The File reads the version of entry.FileVersion, if set, so its content does not change while it is read.
+//
+// If options is nil, the default options are used.
+func (module *Files) Open(ctx context.Context, entry *FileEntry, options *OpenOptions) (*File, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if entry.Size < 0 {
+		return nil, errors.ArgumentInvalid.With("size", entry.Size)
+	}
+	if options == nil {
+		options = &OpenOptions{}
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+	file := &File{
+		Entry:     entry,
+		module:    module,
+		ctx:       ctx,
+		version:   entry.FileVersion.ID,
+		size:      entry.Size,
+		blockSize: options.BlockSize,
+		capacity:  options.CacheBlocks,
+		blocks:    map[int64]*list.Element{},
+		lru:       list.New(),
+	}
+	if file.blockSize <= 0 {
+		file.blockSize = DefaultBlockSize
+	}
+	if file.capacity <= 0 {
+		file.capacity = DefaultCacheBlocks
+	}
+	return file, nil
+}
+
+// Size gives the size of the File
+func (file *File) Size() int64 {
+	return file.size
+}
+
+// ReadAt reads len(data) bytes from the File starting at byte offset
+//
+// implements io.ReaderAt
+func (file *File) ReadAt(data []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, errors.ArgumentInvalid.With("offset", offset)
+	}
+	if offset >= file.size {
+		return 0, io.EOF
+	}
+	read := 0
+	for read < len(data) && offset < file.size {
+		block, err := file.block(offset / file.blockSize)
+		if err != nil {
+			return read, err
+		}
+		copied := copy(data[read:], block[offset%file.blockSize:])
+		read += copied
+		offset += int64(copied)
+	}
+	if read < len(data) {
+		return read, io.EOF
+	}
+	return read, nil
+}
+
+// Read reads up to len(data) bytes from the File at the current offset
+//
+// implements io.Reader
+func (file *File) Read(data []byte) (int, error) {
+	read, err := file.ReadAt(data, file.offset)
+	file.offset += int64(read)
+	if err == io.EOF && read > 0 {
+		return read, nil
+	}
+	return read, err
+}
+
+// Seek sets the offset for the next Read
+//
+// implements io.Seeker
+func (file *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.size
+	default:
+		return 0, errors.ArgumentInvalid.With("whence", whence)
+	}
+	if offset < 0 {
+		return 0, errors.ArgumentInvalid.With("offset", offset)
+	}
+	file.offset = offset
+	return offset, nil
+}
+
+// Close releases the cached blocks of the File
+//
+// implements io.Closer
+func (file *File) Close() error {
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	file.closed = true
+	file.blocks = map[int64]*list.Element{}
+	file.lru.Init()
+	return nil
+}
+
+// block gives the data of the block at the given index, from the cache or from Box.com
+func (file *File) block(index int64) ([]byte, error) {
+	file.mutex.Lock()
+	if file.closed {
+		file.mutex.Unlock()
+		return nil, errors.NotInitialized.With("file")
+	}
+	if element, ok := file.blocks[index]; ok {
+		file.lru.MoveToFront(element)
+		file.mutex.Unlock()
+		return element.Value.(*fileBlock).data, nil
+	}
+	file.mutex.Unlock()
+
+	offset := index * file.blockSize
+	length := file.blockSize
+	if offset+length > file.size {
+		length = file.size - offset
+	}
+	buffer := bytes.NewBuffer(make([]byte, 0, length))
+	if _, _, err := file.module.download(file.ctx, file.Entry, buffer, &DownloadOptions{Version: file.version, Offset: offset, Length: length}); err != nil {
+		return nil, err
+	}
+	if int64(buffer.Len()) != length {
+		return nil, errors.WithStack(io.ErrUnexpectedEOF)
+	}
+
+	file.mutex.Lock()
+	defer file.mutex.Unlock()
+	if element, ok := file.blocks[index]; ok { // another goroutine fetched it meanwhile
+		file.lru.MoveToFront(element)
+		return element.Value.(*fileBlock).data, nil
+	}
+	file.blocks[index] = file.lru.PushFront(&fileBlock{index: index, data: buffer.Bytes()})
+	for file.lru.Len() > file.capacity {
+		oldest := file.lru.Back()
+		file.lru.Remove(oldest)
+		delete(file.blocks, oldest.Value.(*fileBlock).index)
+	}
+	return buffer.Bytes(), nil
+}
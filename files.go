@@ -3,6 +3,9 @@ package box
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -39,6 +42,9 @@ type FileEntry struct {
 	FileVersion       FileVersion    `json:"file_version"`
 	Parent            PathEntry      `json:"parent"`
 	Paths             PathCollection `json:"path_collection"`
+	Tags              []string       `json:"tags,omitempty"`
+	Collections       []PathEntry    `json:"collections,omitempty"`
+	Lock              *FileLock      `json:"lock,omitempty"`
 	CreatedAt         time.Time      `json:"-"`
 	ModifiedAt        time.Time      `json:"-"`
 	TrashedAt         time.Time      `json:"-"`
@@ -52,9 +58,20 @@ type FileEntry struct {
 
 // FileVersion represents the version of a FileEntry
 type FileVersion struct {
-	Type     string `json:"type"`
-	ID       string `json:"id"`
-	Checksum string `json:"sha1"`
+	Type                string     `json:"type"`
+	ID                  string     `json:"id"`
+	Checksum            string     `json:"sha1"`
+	Name                string     `json:"name,omitempty"`
+	Size                int64      `json:"size,omitempty"`
+	UploaderDisplayName string     `json:"uploader_display_name,omitempty"`
+	ModifiedBy          *UserEntry `json:"modified_by,omitempty"`
+	TrashedBy           *UserEntry `json:"trashed_by,omitempty"`
+	RestoredBy          *UserEntry `json:"restored_by,omitempty"`
+	CreatedAt           time.Time  `json:"-"`
+	ModifiedAt          time.Time  `json:"-"`
+	TrashedAt           time.Time  `json:"-"`
+	RestoredAt          time.Time  `json:"-"`
+	PurgedAt            time.Time  `json:"-"`
 }
 
 // PathCollection represents a collection of PathEntry
@@ -75,6 +92,11 @@ type PathEntry struct {
 	Checksum   string `json:"sha1,omitempty"`
 }
 
+// Entry is implemented by the entries that can be given as a PathEntry (PathEntry, FileEntry and FolderEntry)
+type Entry interface {
+	AsPathEntry() *PathEntry
+}
+
 // UserEntry represents a User in a FileEntry
 type UserEntry struct {
 	Type  string `json:"type"`
@@ -83,17 +105,121 @@ type UserEntry struct {
 	Login string `json:"login"`
 }
 
+// FileUpdateOptions contains the changes to apply to a file
+//
+// Only the fields that are set are changed
+type FileUpdateOptions struct {
+	// Name renames the file
+	Name string
+	// Parent moves the file to another folder
+	Parent *PathEntry
+	// Description changes the description of the file, a pointer to an empty string removes it
+	Description *string
+	// Tags replaces the tags of the file, an empty non-nil slice removes all tags
+	Tags []string
+	// Collections replaces the collections (by their ID) the file belongs to, an empty non-nil slice removes the file from all collections
+	Collections []string
+	// SharedLink creates or updates the shared link of the file
+	SharedLink *SharedLinkOptions
+}
+
+// FindOptions contains the options for finding a file
+type FindOptions struct {
+	// Fields are the fields to get, Box.com gives its standard fields if empty
+	//
+	// Box.com only gives the requested fields (and the type, id and etag), e.g. "lock" must be requested to get FileEntry.Lock
+	Fields []string
+}
+
 // DownloadOptions contains the options for downloading data
 type DownloadOptions struct {
-	Parent      *PathEntry
-	Filename    string
+	// Version is the ID of the FileVersion to download, the current version is downloaded if empty
+	Version string
+	// Offset is the position of the first byte to download
+	Offset int64
+	// Length is the number of bytes to download, the data is downloaded until the end of the file if 0
+	Length int64
+	// VerifyChecksum tells to verify the downloaded data against the SHA1 of the FileEntry
+	//
+	// Only whole files of the current version can be verified
+	VerifyChecksum bool
+	// ProgressWriter, if not nil, receives the downloaded data as it is written
+	//
+	// If it implements request.ProgressBarMaxSetter or request.ProgressBarMaxChanger,
+	// its maximum is set to the number of bytes to download
+	ProgressWriter io.Writer
+	// OnProgress, if not nil, is called with the progress of the download
+	OnProgress ProgressFunc
+	// Workers is the number of concurrent range requests used by DownloadAt (default: DefaultDownloadWorkers)
+	Workers int
+	// ChunkSize is the size of the ranges requested by DownloadAt (default: DefaultDownloadChunkSize)
+	ChunkSize int64
+
+	// Deprecated: not used, kept for compatibility.
+	Parent *PathEntry
+	// Deprecated: not used, kept for compatibility.
+	Filename string
+	// Deprecated: not used, kept for compatibility.
 	ContentType string
-	Content     []byte
-	Payload     interface{}
+	// Deprecated: not used, kept for compatibility.
+	Content []byte
+	// Deprecated: not used, kept for compatibility.
+	Payload interface{}
+}
+
+// IsRange tells if these options download only a part of the file
+func (options DownloadOptions) IsRange() bool {
+	return options.Offset > 0 || options.Length > 0
+}
+
+// headers gives the HTTP headers for these options
+func (options DownloadOptions) headers() map[string]string {
+	if !options.IsRange() {
+		return nil
+	}
+	if options.Length > 0 {
+		return map[string]string{"Range": fmt.Sprintf("bytes=%d-%d", options.Offset, options.Offset+options.Length-1)}
+	}
+	return map[string]string{"Range": fmt.Sprintf("bytes=%d-", options.Offset)}
+}
+
+// contentURL gives the URL to download the content of the given FileEntry with these options
+func (options DownloadOptions) contentURL(api *url.URL, entry *FileEntry) *url.URL {
+	contentURL, _ := api.Parse(entry.ID + "/content")
+	if len(options.Version) > 0 {
+		contentURL.RawQuery = url.Values{"version": []string{options.Version}}.Encode()
+	}
+	return contentURL
+}
+
+// AsPathEntry gets a PathEntry from the current FileEntry
+//
+// implements Entry
+func (file *FileEntry) AsPathEntry() *PathEntry {
+	if file == nil {
+		return nil
+	}
+	return &PathEntry{
+		Type:       "file",
+		ID:         file.ID,
+		Name:       file.Name,
+		ETag:       file.ETag,
+		SequenceID: file.SequenceID,
+		Checksum:   file.Checksum,
+	}
+}
+
+// AsPathEntry gets the current PathEntry
+//
+// implements Entry
+func (entry *PathEntry) AsPathEntry() *PathEntry {
+	return entry
 }
 
 // FindByID retrieves a file by its id
-func (module *Files) FindByID(ctx context.Context, fileID string) (*FileEntry, error) {
+//
+// The fields of the file can be chosen with options, e.g. to get its lock.
+func (module *Files) FindByID(ctx context.Context, fileID string, options ...*FindOptions) (*FileEntry, error) {
 	// query: fields=comma-separated list of fields to include in the response
 	if len(fileID) == 0 {
 		return nil, errors.ArgumentMissing.With("id")
@@ -103,13 +229,19 @@ func (module *Files) FindByID(ctx context.Context, fileID string) (*FileEntry, e
 	}
 
 	findURL, _ := module.api.Parse(fileID)
+	parameters := map[string]string{}
+	if len(options) > 0 && options[0] != nil && len(options[0].Fields) > 0 {
+		parameters["fields"] = strings.Join(options[0].Fields, ",")
+	}
 	result := FileEntry{}
-	_, err := module.Client.sendRequest(ctx, &request.Options{URL: findURL}, &result)
+	_, err := module.Client.sendRequest(ctx, &request.Options{URL: findURL, Parameters: parameters}, &result)
 	return &result, err
 }
 
 // FindByName retrieves a file by its name
-// For now, exact match and 1 level (no recursion)
+// For now, exact match and 1 level (no recursion), see Paths.Resolve for full paths
+//
+// Names are compared according to Client.CaseSensitive, all the items of the parent folder are searched, page by page.
 func (module *Files) FindByName(ctx context.Context, name string, parent *PathEntry) (*FileEntry, error) {
 	if len(name) == 0 {
 		return nil, errors.ArgumentMissing.With("filename")
@@ -121,19 +253,142 @@ func (module *Files) FindByName(ctx context.Context, name string, parent *PathEn
 		return nil, errors.Unauthorized.WithStack()
 	}
 
-	// First get the parent folder
-	folder, err := module.Client.Folders.FindByID(ctx, parent.ID)
-	if err != nil {
+	items := module.Client.Folders.Items(ctx, parent, nil)
+	for items.Next() {
+		item := items.Item()
+		if item.File != nil && module.Client.sameName(item.File.Name, name) {
+			return module.FindByID(ctx, item.File.ID)
+		}
+	}
+	if err := items.Err(); err != nil {
 		return nil, errors.ArgumentInvalid.With("parent", parent.ID).(errors.Error).Wrap(err)
 	}
+	return nil, errors.NotFound.With("filename", name)
+}
+
+// Update updates a file with the given changes and returns the updated FileEntry
+//
+// If entry.ETag is set, the update is only applied if the file has not changed since, otherwise PreconditionFailed is returned.
+func (module *Files) Update(ctx context.Context, entry *FileEntry, options *FileUpdateOptions) (*FileEntry, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if options == nil {
+		return nil, errors.ArgumentMissing.With("options")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	updateURL, _ := module.api.Parse(entry.ID)
+	result := FileEntry{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:  http.MethodPut,
+		URL:     updateURL,
+		Headers: ifMatch(entry.ETag),
+		Payload: options,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Copy copies a file to the given parent folder and returns the new FileEntry
+//
+// If newName is empty, the copy keeps the name of the file.
+// If version is not empty, that version of the file is copied instead of the current one.
+//
+// If a file with the same name already exists in the parent folder, ItemNameInUse is returned.
+func (module *Files) Copy(ctx context.Context, entry *FileEntry, parent *PathEntry, newName, version string) (*FileEntry, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if parent == nil || len(parent.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("parent")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	copyURL, _ := module.api.Parse(entry.ID + "/copy")
+	result := FileEntry{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL: copyURL,
+		Payload: struct {
+			Parent  PathEntry `json:"parent"`
+			Name    string    `json:"name,omitempty"`
+			Version string    `json:"version,omitempty"`
+		}{PathEntry{ID: parent.ID}, newName, version},
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete deletes a file (it is moved to the trash)
+//
+// If entry.ETag is set, the file is only deleted if it has not changed since, otherwise PreconditionFailed is returned.
+// If the file is locked, AccessDeniedItemLocked is returned.
+func (module *Files) Delete(ctx context.Context, entry *FileEntry) error {
+	if entry == nil || len(entry.ID) == 0 {
+		return errors.ArgumentMissing.With("entry")
+	}
+	if !module.Client.IsAuthenticated() {
+		return errors.Unauthorized.WithStack()
+	}
 
-	name = strings.ToLower(name)
-	for _, item := range folder.ItemCollection.Paths {
-		if item.Type == "file" && strings.ToLower(item.Name) == name { // Case insensitive search, option in the future?
-			return module.FindByID(ctx, item.ID)
+	deleteURL, _ := module.api.Parse(entry.ID)
+	_, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:  http.MethodDelete,
+		URL:     deleteURL,
+		Headers: ifMatch(entry.ETag),
+	}, nil)
+	return err
+}
+
+// ifMatch gives the If-Match header for the given ETag, if any
+func ifMatch(etag string) map[string]string {
+	if len(etag) == 0 {
+		return nil
+	}
+	return map[string]string{"If-Match": etag}
+}
+
+// MarshalJSON marshals this into JSON
+func (options FileUpdateOptions) MarshalJSON() ([]byte, error) {
+	payload := map[string]interface{}{}
+	if len(options.Name) > 0 {
+		payload["name"] = options.Name
+	}
+	if options.Parent != nil && len(options.Parent.ID) > 0 {
+		payload["parent"] = map[string]string{"id": options.Parent.ID}
+	}
+	if options.Description != nil {
+		payload["description"] = *options.Description
+	}
+	if options.Tags != nil {
+		payload["tags"] = options.Tags
+	}
+	if options.Collections != nil {
+		collections := make([]map[string]string, 0, len(options.Collections))
+		for _, id := range options.Collections {
+			collections = append(collections, map[string]string{"id": id})
 		}
+		payload["collections"] = collections
 	}
-	return nil, errors.NotFound.With("filename", name)
+	if options.SharedLink != nil {
+		data, err := json.Marshal(options.SharedLink)
+		if err != nil {
+			return nil, errors.JSONMarshalError.Wrap(err)
+		}
+		var link map[string]json.RawMessage
+		if err := json.Unmarshal(data, &link); err != nil {
+			return nil, errors.JSONMarshalError.Wrap(err)
+		}
+		payload["shared_link"] = link["shared_link"]
+	}
+	data, err := json.Marshal(payload)
+	return data, errors.JSONMarshalError.Wrap(err)
 }
 
 // MarshalJSON marshals this into JSON
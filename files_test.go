@@ -1,9 +1,12 @@
 package box_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -57,7 +60,7 @@ func (suite *FileSuite) TearDownSuite() {
 		suite.Logger.Infof("All tests succeeded, we are cleaning")
 		folder, err := suite.Client.Folders.FindByName(context.Background(), "unit-test")
 		if err == nil {
-			err := suite.Client.Folders.Delete(context.Background(), folder)
+			err := suite.Client.Folders.Delete(context.Background(), folder, true)
 			suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		}
 	}
@@ -99,7 +102,7 @@ func (suite *FileSuite) AfterTest(suiteName, testName string) {
 			err := suite.Client.Auth.Authenticate(context.Background(), suite.FetchCredentials())
 			suite.Require().Nil(err, "Failed to authenticate box.Client")
 		}
-		err := suite.Client.Folders.Delete(context.Background(), suite.Root)
+		err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
 		suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		suite.Root = nil
 	}
@@ -151,6 +154,123 @@ func (suite *FileSuite) TestCanDownload() {
 	suite.Assert().Equal(uint64(13), downloaded.Length)
 }
 
+func (suite *FileSuite) TestCanDownloadWithChecksumVerification() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	suite.Require().Equal(1, collection.Count, "There should be 1 entry in the collection")
+	entry := collection.Files[0]
+	suite.Assert().Equal("0a0a9f2a6772942557ab5355d76af442f8f65e01", entry.Checksum)
+
+	downloaded, err := suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{VerifyChecksum: true})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal(uint64(13), downloaded.Length)
+}
+
+func (suite *FileSuite) TestShouldFailDownloadingWithChecksumMismatch() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+	entry.Checksum = "0000000000000000000000000000000000000000"
+
+	_, err = suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{VerifyChecksum: true})
+	suite.Require().NotNil(err, "Should have failed downloading file")
+	suite.Assert().Truef(errors.Is(err, box.ChecksumMismatch), "Errors should be a Checksum Mismatch Error. Error: %v", err)
+}
+
+func (suite *FileSuite) TestCanDownloadRange() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	downloaded, err := suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{Offset: 7, Length: 5})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal("World", string(downloaded.Data))
+
+	downloaded, err = suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{Offset: 7})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal("World!", string(downloaded.Data))
+}
+
+func (suite *FileSuite) TestCanDownloadVersion() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+	suite.Require().NotEmpty(entry.FileVersion.ID, "The entry should have a version")
+
+	downloaded, err := suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{Version: entry.FileVersion.ID, VerifyChecksum: true})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal("Hello, World!", string(downloaded.Data))
+}
+
+func (suite *FileSuite) TestShouldFailDownloadingRangeWithChecksumVerification() {
+	entry := box.FileEntry{ID: "1234", Checksum: "0a0a9f2a6772942557ab5355d76af442f8f65e01"}
+	_, err := suite.Client.Files.Download(context.Background(), &entry, &box.DownloadOptions{Offset: 7, VerifyChecksum: true})
+	suite.Require().NotNil(err, "Should have failed downloading file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Errors should be an Argument Invalid Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("offset", details.What)
+}
+
+func (suite *FileSuite) TestCanDownloadTo() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	writer := bytes.Buffer{}
+	progress := bytes.Buffer{}
+	written, err := suite.Client.Files.DownloadTo(context.Background(), &entry, &writer, &box.DownloadOptions{VerifyChecksum: true, ProgressWriter: &progress})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal(int64(13), written)
+	suite.Assert().Equal("Hello, World!", writer.String())
+	suite.Assert().Equal(13, progress.Len())
+}
+
+func (suite *FileSuite) TestCanResumeDownloadTo() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	writer := bytes.NewBufferString("Hello, ")
+	written, err := suite.Client.Files.DownloadTo(context.Background(), &entry, writer, &box.DownloadOptions{Offset: int64(writer.Len())})
+	suite.Require().Nilf(err, "Failed downloading a file. Error: %s", err)
+	suite.Assert().Equal(int64(6), written)
+	suite.Assert().Equal("Hello, World!", writer.String())
+}
+
+func (suite *FileSuite) TestShouldFailDownloadingToWithMissingWriter() {
+	_, err := suite.Client.Files.DownloadTo(context.Background(), &box.FileEntry{ID: "1234"}, nil, nil)
+	suite.Require().NotNil(err, "Should have failed downloading file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("writer", details.What)
+}
+
 func (suite *FileSuite) TestCanUploadWithPayload() {
 	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
 		Parent:   suite.Root.AsPathEntry(),
@@ -184,6 +304,131 @@ func (suite *FileSuite) TestCanUploadWithContent() {
 	suite.Assert().Equal("hello.txt", entry.Name)
 }
 
+func (suite *FileSuite) TestCanUploadWithContentTimestamps() {
+	createdAt := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+	modifiedAt := time.Date(2021, time.February, 3, 4, 5, 6, 0, time.UTC)
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:            suite.Root.AsPathEntry(),
+		Filename:          "hello.txt",
+		Description:       "Hello file",
+		ContentCreatedAt:  createdAt,
+		ContentModifiedAt: modifiedAt,
+		Content:           request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	suite.Require().Equal(1, collection.Count, "There should be 1 entry in the collection")
+	entry := collection.Files[0]
+	suite.Assert().Equal("Hello file", entry.Description)
+	suite.Assert().True(createdAt.Equal(entry.ContentCreatedAt), "Content created at should be %s, got %s", createdAt, entry.ContentCreatedAt)
+	suite.Assert().True(modifiedAt.Equal(entry.ContentModifiedAt), "Content modified at should be %s, got %s", modifiedAt, entry.ContentModifiedAt)
+}
+
+func (suite *FileSuite) TestCanUploadFile() {
+	path := filepath.Join(suite.T().TempDir(), "hello.txt")
+	err := os.WriteFile(path, []byte("Hello, World!"), 0600)
+	suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+	modifiedAt := time.Date(2021, time.February, 3, 4, 5, 6, 0, time.UTC)
+	err = os.Chtimes(path, modifiedAt, modifiedAt)
+	suite.Require().Nilf(err, "Failed changing the times of a local file. Error: %s", err)
+
+	collection, err := suite.Client.Files.UploadFile(context.Background(), path, &box.UploadOptions{Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	suite.Require().Equal(1, collection.Count, "There should be 1 entry in the collection")
+	entry := collection.Files[0]
+	suite.Assert().Equal("hello.txt", entry.Name)
+	suite.Assert().True(modifiedAt.Equal(entry.ContentModifiedAt), "Content modified at should be %s, got %s", modifiedAt, entry.ContentModifiedAt)
+}
+
+func (suite *FileSuite) TestShouldFailUploadingFileWithMissingPath() {
+	_, err := suite.Client.Files.UploadFile(context.Background(), "", nil)
+	suite.Require().NotNil(err, "Should have failed uploading file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("path", details.What)
+}
+
+func (suite *FileSuite) TestCanUploadDirectory() {
+	directory := suite.T().TempDir()
+	err := os.MkdirAll(filepath.Join(directory, "sub", "deeper"), 0700)
+	suite.Require().Nilf(err, "Failed creating local folders. Error: %s", err)
+	for _, name := range []string{"hello.txt", "sub/world.txt", "sub/deeper/again.txt"} {
+		err = os.WriteFile(filepath.Join(directory, filepath.FromSlash(name)), []byte("Hello from "+name), 0600)
+		suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+	}
+
+	report, err := suite.Client.Files.UploadDirectory(context.Background(), directory, suite.Root.AsPathEntry(), nil)
+	suite.Require().Nilf(err, "Failed uploading a directory. Error: %s", err)
+	suite.Require().Len(report.Files, 3)
+	suite.Assert().Empty(report.Failed())
+	for _, result := range report.Files {
+		suite.Assert().Equalf("created", result.Status, "File %s should have been created", result.Path)
+	}
+
+	err = os.WriteFile(filepath.Join(directory, "hello.txt"), []byte("Hello again!"), 0600)
+	suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+	report, err = suite.Client.Files.UploadDirectory(context.Background(), directory, suite.Root.AsPathEntry(), &box.UploadDirectoryOptions{Workers: 2})
+	suite.Require().Nilf(err, "Failed uploading a directory again. Error: %s", err)
+	statuses := map[string]string{}
+	for _, result := range report.Files {
+		statuses[result.Path] = result.Status
+	}
+	suite.Assert().Equal(map[string]string{"hello.txt": "updated", "sub/world.txt": "skipped", "sub/deeper/again.txt": "skipped"}, statuses)
+}
+
+func (suite *FileSuite) TestShouldFailUploadingDirectoryWithMissingParent() {
+	_, err := suite.Client.Files.UploadDirectory(context.Background(), suite.T().TempDir(), nil, nil)
+	suite.Require().NotNil(err, "Should have failed uploading directory")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("parent", details.What)
+}
+
+func (suite *FileSuite) TestCanSync() {
+	directory := suite.T().TempDir()
+	err := os.MkdirAll(filepath.Join(directory, "sub"), 0700)
+	suite.Require().Nilf(err, "Failed creating local folders. Error: %s", err)
+	err = os.WriteFile(filepath.Join(directory, "hello.txt"), []byte("Hello, World!"), 0600)
+	suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+	err = os.WriteFile(filepath.Join(directory, "sub", "world.txt"), []byte("Hello from sub"), 0600)
+	suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+
+	report, err := suite.Client.Files.Sync(context.Background(), suite.Root, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing. Error: %s", err)
+	suite.Require().Len(report.Files, 2)
+	for _, result := range report.Files {
+		suite.Assert().Equalf("uploaded", result.Action, "File %s should have been uploaded", result.Path)
+	}
+
+	err = os.WriteFile(filepath.Join(directory, "hello.txt"), []byte("Hello again!"), 0600)
+	suite.Require().Nilf(err, "Failed writing a local file. Error: %s", err)
+	err = os.Remove(filepath.Join(directory, "sub", "world.txt"))
+	suite.Require().Nilf(err, "Failed deleting a local file. Error: %s", err)
+	report, err = suite.Client.Files.Sync(context.Background(), suite.Root, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing again. Error: %s", err)
+	actions := map[string]string{}
+	for _, result := range report.Files {
+		actions[result.Path] = result.Action
+	}
+	suite.Assert().Equal(map[string]string{"hello.txt": "uploaded", "sub/world.txt": "deleted_remote"}, actions)
+}
+
+func (suite *FileSuite) TestCanReportUploadProgress() {
+	reports := []box.Progress{}
+	_, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:     suite.Root.AsPathEntry(),
+		Filename:   "hello.txt",
+		Content:    request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+		OnProgress: func(progress box.Progress) { reports = append(reports, progress) },
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	last := reports[len(reports)-1]
+	suite.Assert().True(last.Done, "The last report should be done")
+	suite.Assert().Equal(last.Total, last.Transferred)
+}
+
 func (suite *FileSuite) TestCanFindByID() {
 	uploaded, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
 		Parent:   suite.Root.AsPathEntry(),
@@ -212,6 +457,168 @@ func (suite *FileSuite) TestCanFindByName() {
 	suite.Assert().Equal("hello.txt", entry.Name)
 }
 
+func (suite *FileSuite) TestCanUpdate() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+
+	description := "Renamed file"
+	updated, err := suite.Client.Files.Update(context.Background(), &entry, &box.FileUpdateOptions{
+		Name:        "renamed.txt",
+		Parent:      subfolder.AsPathEntry(),
+		Description: &description,
+		Tags:        []string{"hello", "world"},
+	})
+	suite.Require().Nilf(err, "Failed updating a file. Error: %s", err)
+	suite.Assert().Equal(entry.ID, updated.ID)
+	suite.Assert().Equal("renamed.txt", updated.Name)
+	suite.Assert().Equal("Renamed file", updated.Description)
+	suite.Assert().Equal(subfolder.ID, updated.Parent.ID)
+}
+
+func (suite *FileSuite) TestShouldFailUpdatingWithOutdatedETag() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+	_, err = suite.Client.Files.Update(context.Background(), &entry, &box.FileUpdateOptions{Name: "renamed.txt"})
+	suite.Require().Nilf(err, "Failed updating a file. Error: %s", err)
+
+	_, err = suite.Client.Files.Update(context.Background(), &entry, &box.FileUpdateOptions{Name: "renamed-again.txt"})
+	suite.Require().NotNil(err, "Should have failed updating file")
+	suite.Assert().Truef(errors.Is(err, box.PreconditionFailed), "Errors should be a Precondition Failed Error. Error: %v", err)
+}
+
+func (suite *FileSuite) TestShouldFailUpdatingWithMissingEntry() {
+	_, err := suite.Client.Files.Update(context.Background(), nil, &box.FileUpdateOptions{Name: "renamed.txt"})
+	suite.Require().NotNil(err, "Should have failed updating file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("entry", details.What)
+}
+
+func (suite *FileSuite) TestCanMarshalFileUpdateOptions() {
+	payload, err := json.Marshal(box.FileUpdateOptions{
+		Name:        "renamed.txt",
+		Parent:      &box.PathEntry{ID: "1234"},
+		Tags:        []string{},
+		Collections: []string{"5678"},
+		SharedLink:  &box.SharedLinkOptions{Access: "open"},
+	})
+	suite.Require().Nilf(err, "Failed marshaling FileUpdateOptions. Error: %s", err)
+	suite.Assert().JSONEq(`{"name":"renamed.txt","parent":{"id":"1234"},"tags":[],"collections":[{"id":"5678"}],"shared_link":{"access":"open","permissions":{}}}`, string(payload))
+
+	description := ""
+	payload, err = json.Marshal(box.FileUpdateOptions{Description: &description})
+	suite.Require().Nilf(err, "Failed marshaling FileUpdateOptions. Error: %s", err)
+	suite.Assert().JSONEq(`{"description":""}`, string(payload))
+}
+
+func (suite *FileSuite) TestCanCopy() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	copied, err := suite.Client.Files.Copy(context.Background(), &entry, suite.Root.AsPathEntry(), "copy.txt", "")
+	suite.Require().Nilf(err, "Failed copying a file. Error: %s", err)
+	suite.Assert().NotEqual(entry.ID, copied.ID)
+	suite.Assert().Equal("copy.txt", copied.Name)
+	suite.Assert().Equal(entry.Checksum, copied.Checksum)
+}
+
+func (suite *FileSuite) TestShouldFailCopyingWithSameName() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	_, err = suite.Client.Files.Copy(context.Background(), &entry, suite.Root.AsPathEntry(), "", "")
+	suite.Require().NotNil(err, "Should have failed copying file")
+	suite.Assert().Truef(errors.Is(err, box.ItemNameInUse), "Errors should be an Item Name In Use Error. Error: %v", err)
+}
+
+func (suite *FileSuite) TestCanDelete() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	err = suite.Client.Files.Delete(context.Background(), &entry)
+	suite.Require().Nilf(err, "Failed deleting a file. Error: %s", err)
+	_, err = suite.Client.Files.FindByName(context.Background(), "hello.txt", suite.Root.AsPathEntry())
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Errors should be a Not Found Error. Error: %v", err)
+}
+
+func (suite *FileSuite) TestShouldFailDeletingWithMissingEntry() {
+	err := suite.Client.Files.Delete(context.Background(), nil)
+	suite.Require().NotNil(err, "Should have failed deleting file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("entry", details.What)
+}
+
+func (suite *FileSuite) TestCanLockAndUnlock() {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	lock, err := suite.Client.Files.Lock(context.Background(), &entry, expiresAt, true)
+	suite.Require().Nilf(err, "Failed locking a file. Error: %s", err)
+	suite.Assert().Equal("lock", lock.Type)
+	suite.Assert().True(lock.IsDownloadPrevented)
+	suite.Assert().True(expiresAt.Equal(lock.ExpiresAt), "Lock should expire at %s, not %s", expiresAt, lock.ExpiresAt)
+	suite.Assert().False(lock.IsExpired())
+
+	err = suite.Client.Files.Unlock(context.Background(), &entry)
+	suite.Require().Nilf(err, "Failed unlocking a file. Error: %s", err)
+}
+
+func (suite *FileSuite) TestShouldFailLockingWithMissingEntry() {
+	_, err := suite.Client.Files.Lock(context.Background(), nil, time.Time{}, false)
+	suite.Require().NotNil(err, "Should have failed locking file")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+}
+
+func (suite *FileSuite) TestCanUnmarshalFileEntryWithLock() {
+	payload := []byte(`{"type":"file","id":"1234","lock":{"type":"lock","id":"5678","app_type":"gsuite","is_download_prevented":true,"created_at":"2024-01-02T03:04:05Z","expired_at":"2024-01-03T03:04:05Z","created_by":{"type":"user","id":"42"}}}`)
+	var entry box.FileEntry
+	err := json.Unmarshal(payload, &entry)
+	suite.Require().Nilf(err, "Failed unmarshaling FileEntry. Error: %s", err)
+	suite.Require().NotNil(entry.Lock, "FileEntry should have a lock")
+	suite.Assert().Equal("5678", entry.Lock.ID)
+	suite.Assert().Equal("gsuite", entry.Lock.AppType)
+	suite.Assert().Equal("42", entry.Lock.CreatedBy.ID)
+	suite.Assert().True(entry.Lock.IsDownloadPrevented)
+	suite.Assert().Equal(time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC), entry.Lock.ExpiresAt.UTC())
+	suite.Assert().True(entry.Lock.IsExpired())
+}
+
 func (suite *FileSuite) TestCanMarshalFileEntry() {
 	entry := &box.FileEntry{}
 	payload, err := json.Marshal(entry)
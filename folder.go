@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gildas/go-core"
@@ -54,19 +54,57 @@ type FolderEntry struct {
 	ModifiedBy        UserEntry `json:"modified_by"`
 	OwnedBy           UserEntry `json:"owned_by"`
 
-	AllowedSharedLinkAccessLevels         []string `json:"allowed_shared_link_access_levels"`
-	AllowedInviteeRoles                   []string `json:"allowed_invitee_roles"`
-	HasCollaborations                     bool     `json:"has_collaborations"`
-	CanNonOwnersInvite                    bool     `json:"can_non_owners_invite"`
-	IsExternallyOwned                     bool     `json:"is_externally_owned"`
-	IsCollaborationRestrictedToEnterprise bool     `json:"is_collaboration_restricted_to_enterprise"`
-	//UploadEmail        nil || {access, email}   `json:"folder_upload_email,omitempty"`
+	AllowedSharedLinkAccessLevels         []string           `json:"allowed_shared_link_access_levels"`
+	AllowedInviteeRoles                   []string           `json:"allowed_invitee_roles"`
+	HasCollaborations                     bool               `json:"has_collaborations"`
+	CanNonOwnersInvite                    bool               `json:"can_non_owners_invite"`
+	IsExternallyOwned                     bool               `json:"is_externally_owned"`
+	IsCollaborationRestrictedToEnterprise bool               `json:"is_collaboration_restricted_to_enterprise"`
+	UploadEmail                           *FolderUploadEmail `json:"folder_upload_email,omitempty"`
 	//watermark_info interface{}
 	//metadata       interface{}
 }
 
+// FolderUploadEmail represents the email address that can be used to upload files to a folder
+type FolderUploadEmail struct {
+	// Access tells who can upload by email: "open" or "collaborators"
+	Access string `json:"access"`
+	Email  string `json:"email,omitempty"`
+}
+
+// FolderUpdateOptions contains the changes to apply to a folder
+//
+// Only the fields that are set are changed
+type FolderUpdateOptions struct {
+	// Name renames the folder
+	Name string
+	// Parent moves the folder to another folder
+	Parent *PathEntry
+	// Description changes the description of the folder, a pointer to an empty string removes it
+	Description *string
+	// Tags replaces the tags of the folder, an empty non-nil slice removes all tags
+	Tags []string
+	// SyncState changes the sync state of the folder: "synced", "not_synced" or "partially_synced"
+	SyncState string
+	// CanNonOwnersInvite tells if collaborators who are not owners can invite other users
+	CanNonOwnersInvite *bool
+	// IsCollaborationRestrictedToEnterprise tells if only users of the enterprise can be invited
+	IsCollaborationRestrictedToEnterprise *bool
+	// UploadEmail enables the upload email of the folder with the given access
+	UploadEmail *FolderUploadEmail
+	// DisableUploadEmail disables the upload email of the folder
+	DisableUploadEmail bool
+	// OwnedBy transfers the ownership of the folder to the given user (only its ID is needed)
+	OwnedBy *UserEntry
+}
+
 // AsPathEntry gets a PathEntry from the current FolderEntry
+//
+// implements Entry
 func (folder *FolderEntry) AsPathEntry() *PathEntry {
+	if folder == nil {
+		return nil
+	}
 	return &PathEntry{
 		Type:       "folder",
 		ID:         folder.ID,
@@ -104,8 +142,238 @@ func (module *Folders) Create(ctx context.Context, entry *FolderEntry) (*FolderE
 	return &result, err
 }
 
-// Delete deletes a folder recursively
-func (module *Folders) Delete(ctx context.Context, entry *FolderEntry) error {
+// Update updates a folder with the given changes and returns the updated FolderEntry
+//
+// If entry.ETag is set, the update is only applied if the folder has not changed since, otherwise PreconditionFailed is returned.
+//
+// Moving a folder inside itself fails with CyclicalFolderStructure and moving a collaborated folder
+// to a private folder without changing its owner fails with CannotMakeCollaboratedSubfolderPrivate.
+func (module *Folders) Update(ctx context.Context, entry *FolderEntry, options *FolderUpdateOptions) (*FolderEntry, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if options == nil {
+		return nil, errors.ArgumentMissing.With("options")
+	}
+	if options.UploadEmail != nil && options.DisableUploadEmail {
+		return nil, errors.ArgumentInvalid.With("uploadEmail", options.UploadEmail.Access)
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	updateURL, _ := module.api.Parse(entry.ID)
+	result := FolderEntry{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:  http.MethodPut,
+		URL:     updateURL,
+		Headers: ifMatch(entry.ETag),
+		Payload: options,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FolderCopyTimeout is the maximum time to wait for Box.com to answer a folder copy
+//
+// Copying a large folder tree can take several minutes.
+const FolderCopyTimeout = 10 * time.Minute
+
+// FolderCopyPollInterval is the delay between 2 checks of the parent folder when the answer of a folder copy was lost
+const FolderCopyPollInterval = 2 * time.Second
+
+// FolderCopyPollAttempts is the maximum number of checks of the parent folder when the answer of a folder copy was lost
+const FolderCopyPollAttempts = 150
+
+// Copy copies a folder and all its content to the given parent folder and returns the new FolderEntry
+//
+// If newName is empty, the copy keeps the name of the source folder.
+//
+// Box.com copies folders synchronously, which can take a long time for large folder trees.
+// If the request times out or a gateway gives up before Box.com answers, the copy is still going on,
+// so the parent folder is checked every FolderCopyPollInterval until the copy appears, at most FolderCopyPollAttempts times
+// or until the context is done. The returned folder may then still be filling up.
+//
+// If an item with the same name already exists in the parent folder, ItemNameInUse is returned.
+func (module *Folders) Copy(ctx context.Context, source *FolderEntry, parent *PathEntry, newName string) (*FolderEntry, error) {
+	if source == nil || len(source.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("source")
+	}
+	if parent == nil || len(parent.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("parent")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	copyURL, _ := module.api.Parse(source.ID + "/copy")
+	result := FolderEntry{}
+	_, err := module.Client.sendRequest(ctx, &request.Options{
+		URL: copyURL,
+		Payload: struct {
+			Parent PathEntry `json:"parent"`
+			Name   string    `json:"name,omitempty"`
+		}{PathEntry{ID: parent.ID}, newName},
+		Timeout:  FolderCopyTimeout,
+		Attempts: 1, // Sending the copy again would conflict with the copy in progress
+	}, &result)
+	if err == nil {
+		return &result, nil
+	}
+	if !isCopyInProgress(err) {
+		return nil, err
+	}
+	copyErr := err
+
+	name := newName
+	if len(name) == 0 {
+		if len(source.Name) == 0 {
+			if source, err = module.FindByID(ctx, source.ID); err != nil {
+				return nil, err
+			}
+		}
+		name = source.Name
+	}
+	log := module.Client.Logger.Child(nil, "copy")
+	for attempt := 1; attempt <= FolderCopyPollAttempts; attempt++ {
+		log.Infof("Folder copy is still in progress, waiting for %s before looking for %s", FolderCopyPollInterval, name)
+		select {
+		case <-ctx.Done():
+			return nil, errors.WithStack(ctx.Err())
+		case <-time.After(FolderCopyPollInterval):
+		}
+		folder, err := module.findCopy(ctx, parent, name, source.ID)
+		if err != nil || folder != nil {
+			return folder, err
+		}
+	}
+	log.Errorf("Folder %s did not appear after %d checks", name, FolderCopyPollAttempts)
+	return nil, copyErr
+}
+
+// findCopy finds the copy of the source folder with the given name in the parent folder
+//
+// If the copy is not found, findCopy returns nil without error.
+func (module *Folders) findCopy(ctx context.Context, parent *PathEntry, name, sourceID string) (*FolderEntry, error) {
+	items := module.Items(ctx, parent, &ItemsOptions{Fields: []string{"type", "id", "name"}})
+	for items.Next() {
+		item := items.Item()
+		if item.Folder != nil && item.Folder.ID != sourceID && module.Client.sameName(item.Folder.Name, name) {
+			return module.FindByID(ctx, item.Folder.ID)
+		}
+	}
+	return nil, items.Err()
+}
+
+// isCopyInProgress tells if the error of a copy request means the copy is still going on at Box.com
+func isCopyInProgress(err error) bool {
+	var details *RequestError
+	if errors.As(err, &details) {
+		return details.StatusCode == http.StatusBadGateway || details.StatusCode == http.StatusGatewayTimeout
+	}
+	return errors.Is(err, errors.HTTPStatusRequestTimeout) ||
+		errors.Is(err, errors.HTTPBadGateway) ||
+		errors.Is(err, errors.HTTPStatusGatewayTimeout)
+}
+
+// CreatePathAttempts is the number of times CreatePath tries to create a folder whose name is temporarily reserved
+const CreatePathAttempts = 5
+
+// CreatePath creates the folders of the given path (e.g.: "/Clients/ACME/2024"), like mkdir -p
+//
+// The path starts from the root folder, existing folders are used as they are.
+// When several workers create the same path concurrently, Box.com may answer NameTemporarilyReserved
+// while another worker creates a folder, CreatePath then waits and tries again.
+//
+// If an item of the path is a file, ItemNameInUse is returned.
+func (module *Folders) CreatePath(ctx context.Context, path string) (*FolderEntry, error) {
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		return nil, errors.ArgumentMissing.With("path")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	var folder *FolderEntry
+	parent := &PathEntry{Type: "folder", ID: "0"}
+	for index, name := range segments {
+		created, err := module.createOrGet(ctx, name, parent, index == len(segments)-1)
+		if err != nil {
+			return nil, err
+		}
+		folder = created
+		parent = folder.AsPathEntry()
+	}
+	return folder, nil
+}
+
+// createOrGet creates a folder or gets it if it already exists
+//
+// If full is false, the returned FolderEntry of an existing folder may only contain its ID, name and ETag.
+func (module *Folders) createOrGet(ctx context.Context, name string, parent *PathEntry, full bool) (*FolderEntry, error) {
+	log := module.Client.Logger.Child(nil, "createpath")
+	for attempt := 1; ; attempt++ {
+		folder, err := module.Create(ctx, &FolderEntry{Name: name, Parent: parent})
+		if err == nil {
+			return folder, nil
+		}
+		if errors.Is(err, NameTemporarilyReserved) && attempt < CreatePathAttempts {
+			log.Debugf("Folder %s is being created by someone else, waiting for %s before trying again", name, DefaultRetryAfter)
+			select {
+			case <-ctx.Done():
+				return nil, errors.WithStack(ctx.Err())
+			case <-time.After(DefaultRetryAfter):
+			}
+			continue
+		}
+		if !errors.Is(err, ItemNameInUse) {
+			return nil, err
+		}
+
+		var details *RequestError
+		if errors.As(err, &details) && details.ContextInfo != nil {
+			for _, conflict := range details.ContextInfo.Conflicts {
+				if conflict.Type == "folder" && len(conflict.ID) > 0 {
+					if full {
+						return module.FindByID(ctx, conflict.ID)
+					}
+					return &FolderEntry{Type: "folder", ID: conflict.ID, Name: conflict.Name, ETag: conflict.ETag, SequenceID: conflict.SequenceID}, nil
+				}
+			}
+			if len(details.ContextInfo.Conflicts) > 0 {
+				return nil, err
+			}
+		}
+		// Box.com did not tell which item conflicts, it is searched in the parent folder
+		items := module.Items(ctx, parent, nil)
+		for items.Next() {
+			item := items.Item()
+			if module.Client.sameName(item.Name(), name) {
+				if item.Folder == nil {
+					return nil, err
+				}
+				if full {
+					return module.FindByID(ctx, item.Folder.ID)
+				}
+				return item.Folder, nil
+			}
+		}
+		if items.Err() != nil {
+			return nil, items.Err()
+		}
+		return nil, err
+	}
+}
+
+// Delete deletes a folder (it is moved to the trash)
+//
+// If recursive is false and the folder is not empty, FolderNotEmpty is returned.
+// If recursive is true, the folder is deleted with all its content.
+//
+// If entry.ETag is set, the folder is only deleted if it has not changed since, otherwise PreconditionFailed is returned.
+func (module *Folders) Delete(ctx context.Context, entry *FolderEntry, recursive bool) error {
 	if entry == nil || len(entry.ID) == 0 {
 		return errors.ArgumentMissing.With("ID")
 	}
@@ -116,7 +384,8 @@ func (module *Folders) Delete(ctx context.Context, entry *FolderEntry) error {
 	_, err := module.Client.sendRequest(ctx, &request.Options{
 		Method:     http.MethodDelete,
 		URL:        deleteURL,
-		Parameters: map[string]string{"recursive": "true"},
+		Headers:    ifMatch(entry.ETag),
+		Parameters: map[string]string{"recursive": strconv.FormatBool(recursive)},
 	}, nil)
 	return err
 }
@@ -140,7 +409,9 @@ func (module *Folders) FindByID(ctx context.Context, folderID string) (*FolderEn
 }
 
 // FindByName retrieves a folder by its name
-// For now, exact match and 1 level (no recursion)
+// For now, exact match and 1 level (no recursion), see Paths.Resolve for full paths
+//
+// Names are compared according to Client.CaseSensitive, all the items of the root folder are searched, page by page.
 func (module *Folders) FindByName(ctx context.Context, name string) (*FolderEntry, error) {
 	if len(name) == 0 {
 		return nil, errors.ArgumentMissing.With("name")
@@ -149,22 +420,53 @@ func (module *Folders) FindByName(ctx context.Context, name string) (*FolderEntr
 		return nil, errors.Unauthorized.WithStack()
 	}
 
-	// First get the root folder
-	findURL, _ := module.api.Parse("0")
-	root := FolderEntry{}
-	if _, err := module.Client.sendRequest(ctx, &request.Options{
-		URL: findURL,
-	}, &root); err != nil {
+	items := module.Items(ctx, &PathEntry{Type: "folder", ID: "0"}, nil)
+	for items.Next() {
+		item := items.Item()
+		if item.Folder != nil && module.Client.sameName(item.Folder.Name, name) {
+			return module.FindByID(ctx, item.Folder.ID)
+		}
+	}
+	if err := items.Err(); err != nil {
 		return nil, err
 	}
+	return nil, errors.NotFound.With("folder", name)
+}
 
-	name = strings.ToLower(name)
-	for _, item := range root.ItemCollection.Paths {
-		if item.Type == "folder" && strings.ToLower(item.Name) == name { // Case insensitive search, option in the future?
-			return module.FindByID(ctx, item.ID)
-		}
+// MarshalJSON marshals this into JSON
+func (options FolderUpdateOptions) MarshalJSON() ([]byte, error) {
+	payload := map[string]interface{}{}
+	if len(options.Name) > 0 {
+		payload["name"] = options.Name
 	}
-	return nil, errors.NotFound.With("folder", name)
+	if options.Parent != nil && len(options.Parent.ID) > 0 {
+		payload["parent"] = map[string]string{"id": options.Parent.ID}
+	}
+	if options.Description != nil {
+		payload["description"] = *options.Description
+	}
+	if options.Tags != nil {
+		payload["tags"] = options.Tags
+	}
+	if len(options.SyncState) > 0 {
+		payload["sync_state"] = options.SyncState
+	}
+	if options.CanNonOwnersInvite != nil {
+		payload["can_non_owners_invite"] = *options.CanNonOwnersInvite
+	}
+	if options.IsCollaborationRestrictedToEnterprise != nil {
+		payload["is_collaboration_restricted_to_enterprise"] = *options.IsCollaborationRestrictedToEnterprise
+	}
+	if options.UploadEmail != nil {
+		payload["folder_upload_email"] = map[string]string{"access": options.UploadEmail.Access}
+	} else if options.DisableUploadEmail {
+		payload["folder_upload_email"] = nil
+	}
+	if options.OwnedBy != nil && len(options.OwnedBy.ID) > 0 {
+		payload["owned_by"] = map[string]string{"id": options.OwnedBy.ID}
+	}
+	data, err := json.Marshal(payload)
+	return data, errors.JSONMarshalError.Wrap(err)
 }
 
 // MarshalJSON marshals this into JSON
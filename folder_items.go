@@ -0,0 +1,243 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// ItemsOptions contains the options for listing the items of a folder
+type ItemsOptions struct {
+	// Fields are the fields to get for each item, Box.com gives a few basic fields if empty
+	Fields []string
+	// Sort sorts the items by "id", "name", "date" or "size"
+	//
+	// Sorted items are paginated with offsets, other items are paginated with markers, which is faster on large folders.
+	Sort string
+	// Direction is the direction of the sort: "ASC" or "DESC", it is ignored if Sort is empty
+	Direction string
+	// PageSize is the number of items fetched per request (default: DefaultItemsPageSize, maximum: 1000)
+	PageSize int
+}
+
+// DefaultItemsPageSize is the number of items fetched per request when listing the items of a folder
+const DefaultItemsPageSize = 1000
+
+// Item represents an item of a folder
+//
+// Only the entry matching the Type of the item is set
+type Item struct {
+	Type    string
+	File    *FileEntry
+	Folder  *FolderEntry
+	WebLink *WebLinkEntry
+}
+
+// WebLinkEntry represents a Web Link Entry
+type WebLinkEntry struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	ETag       string `json:"etag"`
+	SequenceID string `json:"sequence_id"`
+	URL        string `json:"url"`
+}
+
+// FolderItems iterates over the items of a folder, fetching pages from Box.com as needed
+//
+// Usage:
+//
+//	items := client.Folders.Items(ctx, folder.AsPathEntry(), nil)
+//	for items.Next() {
+//	  item := items.Item()
+//	}
+//	if err := items.Err(); err != nil {
+//	  ...
+//	}
+type FolderItems struct {
+	ctx      context.Context
+	module   *Folders
+	folderID string
+	options  ItemsOptions
+	page     []Item
+	index    int
+	offset   int
+	marker   string
+	last     bool
+	err      error
+	attempts uint // number of attempts of each request, go-request's default if 0
+}
+
+// Items lists lazily the items of the given folder
+//
+// Errors are reported by FolderItems.Err once FolderItems.Next returns false.
+func (module *Folders) Items(ctx context.Context, folder *PathEntry, options *ItemsOptions) *FolderItems {
+	items := &FolderItems{ctx: ctx, module: module, index: -1}
+	if options != nil {
+		items.options = *options
+	}
+	if items.options.PageSize <= 0 || items.options.PageSize > DefaultItemsPageSize {
+		items.options.PageSize = DefaultItemsPageSize
+	}
+	if folder == nil || len(folder.ID) == 0 {
+		items.err = errors.ArgumentMissing.With("folder")
+		return items
+	}
+	items.folderID = folder.ID
+	return items
+}
+
+// Next moves to the next item, it returns false when there are no more items or when an error occurred
+func (items *FolderItems) Next() bool {
+	if items.err != nil {
+		return false
+	}
+	items.index++
+	if items.index < len(items.page) {
+		return true
+	}
+	// Box.com may give empty pages before the last one
+	for !items.last {
+		if items.err = items.fetch(); items.err != nil {
+			return false
+		}
+		if len(items.page) > 0 {
+			items.index = 0
+			return true
+		}
+	}
+	return false
+}
+
+// Item gives the current item
+func (items *FolderItems) Item() *Item {
+	if items.index < 0 || items.index >= len(items.page) {
+		return nil
+	}
+	return &items.page[items.index]
+}
+
+// Err gives the error that stopped the iteration, if any
+func (items *FolderItems) Err() error {
+	return items.err
+}
+
+// fetch fetches the next page of items
+func (items *FolderItems) fetch() error {
+	if !items.module.Client.IsAuthenticated() {
+		return errors.Unauthorized.WithStack()
+	}
+	parameters := map[string]string{"limit": strconv.Itoa(items.options.PageSize)}
+	if len(items.options.Fields) > 0 {
+		parameters["fields"] = strings.Join(items.options.Fields, ",")
+	}
+	useMarker := len(items.options.Sort) == 0
+	if useMarker {
+		parameters["usemarker"] = "true"
+		if len(items.marker) > 0 {
+			parameters["marker"] = items.marker
+		}
+	} else {
+		parameters["sort"] = items.options.Sort
+		parameters["offset"] = strconv.Itoa(items.offset)
+		if len(items.options.Direction) > 0 {
+			parameters["direction"] = items.options.Direction
+		}
+	}
+
+	itemsURL, _ := items.module.api.Parse(items.folderID + "/items")
+	result := struct {
+		Count      int    `json:"total_count"`
+		NextMarker string `json:"next_marker"`
+		Entries    []Item `json:"entries"`
+	}{}
+	if _, err := items.module.Client.sendRequest(items.ctx, &request.Options{
+		URL:                       itemsURL,
+		Parameters:                parameters,
+		Attempts:                  items.attempts,
+		InterAttemptUseRetryAfter: true,
+	}, &result); err != nil {
+		return err
+	}
+	items.page = result.Entries
+	if useMarker {
+		items.marker = result.NextMarker
+		items.last = len(result.NextMarker) == 0
+	} else {
+		items.offset += len(result.Entries)
+		items.last = len(result.Entries) == 0 || items.offset >= result.Count
+	}
+	return nil
+}
+
+// ID gives the ID of the entry of this item
+func (item Item) ID() string {
+	switch {
+	case item.File != nil:
+		return item.File.ID
+	case item.Folder != nil:
+		return item.Folder.ID
+	case item.WebLink != nil:
+		return item.WebLink.ID
+	}
+	return ""
+}
+
+// Name gives the name of the entry of this item
+func (item Item) Name() string {
+	switch {
+	case item.File != nil:
+		return item.File.Name
+	case item.Folder != nil:
+		return item.Folder.Name
+	case item.WebLink != nil:
+		return item.WebLink.Name
+	}
+	return ""
+}
+
+// MarshalJSON marshals this into JSON
+func (item Item) MarshalJSON() ([]byte, error) {
+	var entry interface{}
+	switch {
+	case item.File != nil:
+		entry = item.File
+	case item.Folder != nil:
+		entry = item.Folder
+	case item.WebLink != nil:
+		entry = item.WebLink
+	default:
+		entry = struct {
+			Type string `json:"type"`
+		}{item.Type}
+	}
+	data, err := json.Marshal(entry)
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON decodes JSON
+func (item *Item) UnmarshalJSON(payload []byte) (err error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err = json.Unmarshal(payload, &header); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	*item = Item{Type: header.Type}
+	switch header.Type {
+	case "file":
+		item.File = &FileEntry{}
+		err = json.Unmarshal(payload, item.File)
+	case "folder":
+		item.Folder = &FolderEntry{}
+		err = json.Unmarshal(payload, item.Folder)
+	case "web_link":
+		item.WebLink = &WebLinkEntry{}
+		err = json.Unmarshal(payload, item.WebLink)
+	}
+	return errors.JSONUnmarshalError.Wrap(err)
+}
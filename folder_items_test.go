@@ -0,0 +1,172 @@
+package box
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type FolderItemsSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+}
+
+func TestFolderItemsSuite(t *testing.T) {
+	suite.Run(t, new(FolderItemsSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *FolderItemsSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *FolderItemsSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *FolderItemsSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *FolderItemsSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *FolderItemsSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		if req.URL.Path != "/2.0/folders/1234/items" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		query := req.URL.Query()
+		suite.Assert().Equal("2", query.Get("limit"))
+		res.Header().Set("Content-Type", "application/json")
+		if query.Get("usemarker") == "true" {
+			suite.Assert().Empty(query.Get("sort"))
+			suite.Assert().Empty(query.Get("direction"), "direction should only be sent with offsets")
+			switch query.Get("marker") {
+			case "":
+				_, _ = res.Write([]byte(`{"entries":[{"type":"file","id":"1","name":"hello.txt","sha1":"abcd"},{"type":"folder","id":"2","name":"folder"}],"limit":2,"next_marker":"empty"}`))
+			case "empty":
+				_, _ = res.Write([]byte(`{"entries":[],"limit":2,"next_marker":"page2"}`))
+			case "page2":
+				_, _ = res.Write([]byte(`{"entries":[{"type":"web_link","id":"3","name":"link","url":"https://www.box.com"}],"limit":2,"next_marker":""}`))
+			default:
+				res.WriteHeader(http.StatusBadRequest)
+			}
+			return
+		}
+		suite.Assert().Equal("name", query.Get("sort"))
+		suite.Assert().Equal("DESC", query.Get("direction"))
+		suite.Assert().Equal("name,sha1", query.Get("fields"))
+		switch query.Get("offset") {
+		case "0":
+			_, _ = res.Write([]byte(`{"total_count":3,"entries":[{"type":"web_link","id":"3","name":"link"},{"type":"file","id":"1","name":"hello.txt"}],"offset":0,"limit":2}`))
+		case "2":
+			_, _ = res.Write([]byte(`{"total_count":3,"entries":[{"type":"folder","id":"2","name":"folder"}],"offset":2,"limit":2}`))
+		default:
+			res.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func (suite *FolderItemsSuite) TestCanListItemsWithMarkers() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	items := client.Folders.Items(context.Background(), &PathEntry{Type: "folder", ID: "1234"}, &ItemsOptions{PageSize: 2, Direction: "DESC"})
+	ids := []string{}
+	for items.Next() {
+		ids = append(ids, items.Item().ID())
+	}
+	suite.Require().Nilf(items.Err(), "Failed listing items. Error: %s", items.Err())
+	suite.Assert().Equal([]string{"1", "2", "3"}, ids)
+}
+
+func (suite *FolderItemsSuite) TestCanListSortedItemsWithOffsets() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	items := client.Folders.Items(context.Background(), &PathEntry{Type: "folder", ID: "1234"}, &ItemsOptions{
+		Fields:    []string{"name", "sha1"},
+		Sort:      "name",
+		Direction: "DESC",
+		PageSize:  2,
+	})
+	names := []string{}
+	for items.Next() {
+		names = append(names, items.Item().Name())
+	}
+	suite.Require().Nilf(items.Err(), "Failed listing items. Error: %s", items.Err())
+	suite.Assert().Equal([]string{"link", "hello.txt", "folder"}, names)
+}
+
+func (suite *FolderItemsSuite) TestCanDecodeTypedItems() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	items := client.Folders.Items(context.Background(), &PathEntry{Type: "folder", ID: "1234"}, &ItemsOptions{PageSize: 2})
+	suite.Require().True(items.Next())
+	suite.Require().NotNil(items.Item().File)
+	suite.Assert().Equal("abcd", items.Item().File.Checksum)
+	suite.Require().True(items.Next())
+	suite.Require().NotNil(items.Item().Folder)
+	suite.Assert().Equal("folder", items.Item().Folder.Name)
+	suite.Require().True(items.Next())
+	suite.Require().NotNil(items.Item().WebLink)
+	suite.Assert().Equal("https://www.box.com", items.Item().WebLink.URL)
+	suite.Assert().False(items.Next())
+	suite.Assert().Nil(items.Item())
+}
+
+func (suite *FolderItemsSuite) TestShouldFailListingItemsOfUnknownFolder() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	items := client.Folders.Items(context.Background(), &PathEntry{Type: "folder", ID: "4321"}, &ItemsOptions{PageSize: 2})
+	suite.Assert().False(items.Next())
+	suite.Require().NotNil(items.Err(), "Should have failed listing items")
+	suite.Assert().Truef(errors.Is(items.Err(), errors.NotFound), "Error should be a Not Found Error. Error: %v", items.Err())
+}
+
+func (suite *FolderItemsSuite) TestShouldFailListingItemsWithMissingFolder() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	items := client.Folders.Items(context.Background(), nil, nil)
+	suite.Assert().False(items.Next())
+	suite.Assert().Truef(errors.Is(items.Err(), errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", items.Err())
+}
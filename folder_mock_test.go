@@ -0,0 +1,144 @@
+package box
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type FolderMockSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Tree      *fakeTree
+}
+
+func TestFolderMockSuite(t *testing.T) {
+	suite.Run(t, new(FolderMockSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *FolderMockSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Tree = &fakeTree{}
+	suite.Server = httptest.NewServer(suite.Tree)
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *FolderMockSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *FolderMockSuite) BeforeTest(suiteName, testName string) {
+	suite.Tree.Reset()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *FolderMockSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *FolderMockSuite) TestCanCreatePath() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	folder, err := client.Folders.CreatePath(context.Background(), "/Clients/ACME/2024/Q1")
+	suite.Require().Nilf(err, "Failed creating path. Error: %s", err)
+	suite.Assert().Equal("Q1", folder.Name)
+
+	resolved, err := client.Paths.ResolveFolder(context.Background(), "/Clients/ACME/2024/Q1")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal(folder.ID, resolved.ID)
+}
+
+func (suite *FolderMockSuite) TestCanCreateExistingPath() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	folder, err := client.Folders.CreatePath(context.Background(), "/clients/acme")
+	suite.Require().Nilf(err, "Failed creating path. Error: %s", err)
+	suite.Assert().Equal("2", folder.ID)
+	suite.Assert().Equal("ACME", folder.Name)
+	suite.Assert().Len(folder.Paths.Paths, 2, "The existing folder should have been fetched entirely")
+}
+
+func (suite *FolderMockSuite) TestCanCreatePathWithReservedName() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	suite.Tree.Mutex.Lock()
+	suite.Tree.Reserved = 1
+	suite.Tree.Mutex.Unlock()
+	folder, err := client.Folders.CreatePath(context.Background(), "/Clients/Globex")
+	suite.Require().Nilf(err, "Failed creating path. Error: %s", err)
+	suite.Assert().Equal("Globex", folder.Name)
+}
+
+func (suite *FolderMockSuite) TestShouldFailCreatingPathThroughFile() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Folders.CreatePath(context.Background(), "/Clients/ACME/report.pdf/2024")
+	suite.Require().NotNil(err, "Should have failed creating path")
+	suite.Assert().Truef(errors.Is(err, ItemNameInUse), "Error should be an Item Name In Use Error. Error: %v", err)
+}
+
+func (suite *FolderMockSuite) TestCanCopyFolder() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	folder, err := client.Folders.Copy(context.Background(), &FolderEntry{ID: "2"}, &PathEntry{ID: "1"}, "Globex")
+	suite.Require().Nilf(err, "Failed copying folder. Error: %s", err)
+	suite.Assert().Equal("Globex", folder.Name)
+	suite.Assert().NotEqual("2", folder.ID)
+}
+
+func (suite *FolderMockSuite) TestCanCopyFolderWhenAnswerIsLost() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	suite.Tree.Mutex.Lock()
+	suite.Tree.CopyLost = true
+	suite.Tree.Mutex.Unlock()
+	folder, err := client.Folders.Copy(context.Background(), &FolderEntry{ID: "2"}, &PathEntry{ID: "0"}, "")
+	suite.Require().Nilf(err, "Failed copying folder. Error: %s", err)
+	suite.Assert().Equal("ACME", folder.Name)
+	suite.Assert().NotEqual("2", folder.ID)
+	suite.Assert().Equal("0", folder.Paths.Paths[0].ID)
+}
+
+func (suite *FolderMockSuite) TestShouldFailCopyingFolderWithMissingParent() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Folders.Copy(context.Background(), &FolderEntry{ID: "2"}, nil, "")
+	suite.Require().NotNil(err, "Should have failed copying folder")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("parent", details.What)
+}
@@ -56,7 +56,7 @@ func (suite *FolderSuite) TearDownSuite() {
 		suite.Logger.Infof("All tests succeeded, we are cleaning")
 		folder, err := suite.Client.Folders.FindByName(context.Background(), "unit-test")
 		if err == nil {
-			err := suite.Client.Folders.Delete(context.Background(), folder)
+			err := suite.Client.Folders.Delete(context.Background(), folder, true)
 			suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		}
 	}
@@ -98,7 +98,7 @@ func (suite *FolderSuite) AfterTest(suiteName, testName string) {
 			err := suite.Client.Auth.Authenticate(context.Background(), suite.FetchCredentials())
 			suite.Require().Nil(err, "Failed to authenticate box.Client")
 		}
-		err := suite.Client.Folders.Delete(context.Background(), suite.Root)
+		err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
 		suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		suite.Root = nil
 	}
@@ -141,7 +141,7 @@ func (suite *FolderSuite) TestCanCreate() {
 
 func (suite *FolderSuite) TestCanDelete() {
 	if suite.Root != nil {
-		err := suite.Client.Folders.Delete(context.Background(), suite.Root)
+		err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
 		suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		suite.Root = nil
 	}
@@ -156,6 +156,18 @@ func (suite *FolderSuite) TestCanCreateSubFolder() {
 	suite.Require().NotNil(sub, "Subfolder entry should not be nil")
 }
 
+func (suite *FolderSuite) TestCanCreatePath() {
+	folder, err := suite.Client.Folders.CreatePath(context.Background(), "/unit-test/a/b/c")
+	suite.Require().Nilf(err, "Failed creating a path. Error: %s", err)
+	suite.Assert().Equal("c", folder.Name)
+	suite.Require().Len(folder.Paths.Paths, 4)
+	suite.Assert().Equal(suite.Root.ID, folder.Paths.Paths[1].ID)
+
+	again, err := suite.Client.Folders.CreatePath(context.Background(), "/unit-test/a/b/c")
+	suite.Require().Nilf(err, "Failed creating an existing path. Error: %s", err)
+	suite.Assert().Equal(folder.ID, again.ID)
+}
+
 func (suite *FolderSuite) TestCanFindByID() {
 	folder, err := suite.Client.Folders.FindByID(context.Background(), suite.Root.ID)
 	suite.Require().Nilf(err, "Failed finding a folder. Error: %s", err)
@@ -168,6 +180,83 @@ func (suite *FolderSuite) TestCanFindByName() {
 	suite.Assert().Equal(suite.Root, folder)
 }
 
+func (suite *FolderSuite) TestCanUpdate() {
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+	other, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "other", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+
+	canInvite := false
+	description := "Renamed folder"
+	updated, err := suite.Client.Folders.Update(context.Background(), subfolder, &box.FolderUpdateOptions{
+		Name:               "renamed",
+		Parent:             other.AsPathEntry(),
+		Description:        &description,
+		Tags:               []string{"hello", "world"},
+		CanNonOwnersInvite: &canInvite,
+	})
+	suite.Require().Nilf(err, "Failed updating a folder. Error: %s", err)
+	suite.Assert().Equal(subfolder.ID, updated.ID)
+	suite.Assert().Equal("renamed", updated.Name)
+	suite.Assert().Equal("Renamed folder", updated.Description)
+	suite.Assert().Equal(other.ID, updated.Parent.ID)
+	suite.Assert().False(updated.CanNonOwnersInvite)
+}
+
+func (suite *FolderSuite) TestShouldFailUpdatingWithOutdatedETag() {
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+	_, err = suite.Client.Folders.Update(context.Background(), subfolder, &box.FolderUpdateOptions{Name: "renamed"})
+	suite.Require().Nilf(err, "Failed updating a folder. Error: %s", err)
+
+	_, err = suite.Client.Folders.Update(context.Background(), subfolder, &box.FolderUpdateOptions{Name: "renamed-again"})
+	suite.Require().NotNil(err, "Should have failed updating folder")
+	suite.Assert().Truef(errors.Is(err, box.PreconditionFailed), "Errors should be a Precondition Failed Error. Error: %v", err)
+}
+
+func (suite *FolderSuite) TestShouldFailMovingInsideItself() {
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+	root := *suite.Root
+	root.ETag = ""
+	_, err = suite.Client.Folders.Update(context.Background(), &root, &box.FolderUpdateOptions{Parent: subfolder.AsPathEntry()})
+	suite.Require().NotNil(err, "Should have failed moving folder")
+	suite.Assert().Truef(errors.Is(err, box.CyclicalFolderStructure), "Errors should be a Cyclical Folder Structure Error. Error: %v", err)
+}
+
+func (suite *FolderSuite) TestShouldFailUpdatingWithMissingEntry() {
+	_, err := suite.Client.Folders.Update(context.Background(), nil, &box.FolderUpdateOptions{Name: "renamed"})
+	suite.Require().NotNil(err, "Should have failed updating folder")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("entry", details.What)
+}
+
+func (suite *FolderSuite) TestCanMarshalFolderUpdateOptions() {
+	restricted := true
+	payload, err := json.Marshal(box.FolderUpdateOptions{
+		Name:                                  "renamed",
+		Parent:                                &box.PathEntry{ID: "1234"},
+		Tags:                                  []string{},
+		SyncState:                             "synced",
+		IsCollaborationRestrictedToEnterprise: &restricted,
+		UploadEmail:                           &box.FolderUploadEmail{Access: "collaborators"},
+		OwnedBy:                               &box.UserEntry{ID: "5678"},
+	})
+	suite.Require().Nilf(err, "Failed marshaling FolderUpdateOptions. Error: %s", err)
+	suite.Assert().JSONEq(`{"name":"renamed","parent":{"id":"1234"},"tags":[],"sync_state":"synced","is_collaboration_restricted_to_enterprise":true,"folder_upload_email":{"access":"collaborators"},"owned_by":{"id":"5678"}}`, string(payload))
+
+	payload, err = json.Marshal(box.FolderUpdateOptions{DisableUploadEmail: true})
+	suite.Require().Nilf(err, "Failed marshaling FolderUpdateOptions. Error: %s", err)
+	suite.Assert().JSONEq(`{"folder_upload_email":null}`, string(payload))
+
+	description := ""
+	payload, err = json.Marshal(box.FolderUpdateOptions{Description: &description})
+	suite.Require().Nilf(err, "Failed marshaling FolderUpdateOptions. Error: %s", err)
+	suite.Assert().JSONEq(`{"description":""}`, string(payload))
+}
+
 func (suite *FolderSuite) TestShouldFailCreatingWithMissingName() {
 	_, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{})
 	suite.Require().NotNil(err, "Should have failed creating folder")
@@ -196,10 +285,32 @@ func (suite *FolderSuite) TestShouldFailCreatingWithSameName() {
 	suite.Assert().Truef(errors.Is(err, box.ItemNameInUse), "Errors should be an Item Name In Use Error. Error: %v", err)
 }
 
+func (suite *FolderSuite) TestShouldFailDeletingNonEmptyFolder() {
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+	_, err = suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "child", Parent: subfolder.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+
+	err = suite.Client.Folders.Delete(context.Background(), subfolder, false)
+	suite.Require().NotNil(err, "Should have failed deleting a non empty folder")
+	suite.Assert().Truef(errors.Is(err, box.FolderNotEmpty), "Errors should be a Folder Not Empty Error. Error: %v", err)
+}
+
+func (suite *FolderSuite) TestShouldFailDeletingWithOutdatedETag() {
+	subfolder, err := suite.Client.Folders.Create(context.Background(), &box.FolderEntry{Name: "subfolder", Parent: suite.Root.AsPathEntry()})
+	suite.Require().Nilf(err, "Failed creating a folder. Error: %s", err)
+	_, err = suite.Client.Folders.Update(context.Background(), subfolder, &box.FolderUpdateOptions{Name: "renamed"})
+	suite.Require().Nilf(err, "Failed updating a folder. Error: %s", err)
+
+	err = suite.Client.Folders.Delete(context.Background(), subfolder, false)
+	suite.Require().NotNil(err, "Should have failed deleting folder")
+	suite.Assert().Truef(errors.Is(err, box.PreconditionFailed), "Errors should be a Precondition Failed Error. Error: %v", err)
+}
+
 func (suite *FolderSuite) TestShouldFailDeletingWithInvalidID() {
 	folder := *suite.Root
 	folder.ID = "1234"
-	err := suite.Client.Folders.Delete(context.Background(), &folder)
+	err := suite.Client.Folders.Delete(context.Background(), &folder, true)
 	suite.Require().NotNil(err, "Should have failed deleting folder with invalid ID")
 	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Errors should be a Not Found Error. Error: %v", err)
 }
@@ -207,7 +318,7 @@ func (suite *FolderSuite) TestShouldFailDeletingWithInvalidID() {
 func (suite *FolderSuite) TestShouldFailDeletingWithMissingID() {
 	folder := *suite.Root
 	folder.ID = ""
-	err := suite.Client.Folders.Delete(context.Background(), &folder)
+	err := suite.Client.Folders.Delete(context.Background(), &folder, true)
 	suite.Require().NotNil(err, "Should have failed deleting folder without ID")
 	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
 	var details *errors.Error
@@ -219,7 +330,7 @@ func (suite *FolderSuite) TestShouldFailDeletingWhenNotAuthenticated() {
 	if suite.Client.IsAuthenticated() {
 		suite.Client.Auth.Token = nil
 	}
-	err := suite.Client.Folders.Delete(context.Background(), suite.Root)
+	err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
 	suite.Require().NotNil(err, "Should have failed deleting folder when unauthenticated")
 	suite.Assert().Truef(errors.Is(err, errors.Unauthorized), "Errors should be an Unauthorized Error. Error: %v", err)
 }
@@ -0,0 +1,126 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// FileLock represents the lock on a FileEntry
+type FileLock struct {
+	Type                string    `json:"type"`
+	ID                  string    `json:"id"`
+	AppType             string    `json:"app_type,omitempty"`
+	IsDownloadPrevented bool      `json:"is_download_prevented"`
+	CreatedBy           UserEntry `json:"created_by"`
+	CreatedAt           time.Time `json:"-"`
+	ExpiresAt           time.Time `json:"-"`
+}
+
+// IsExpired tells if this lock has expired
+//
+// A lock without expiration never expires
+func (lock FileLock) IsExpired() bool {
+	return !lock.ExpiresAt.IsZero() && time.Now().After(lock.ExpiresAt)
+}
+
+// Lock locks a file so only its owner can change it
+//
+// The lock is also set in entry.Lock. Use FindOptions with the "lock" field to get the lock of a file found later.
+//
+// If expiresAt is the zero time, the lock never expires.
+// If preventDownload is true, other users cannot download the file while it is locked.
+//
+// Changing a locked file from another account fails with AccessDeniedItemLocked.
+func (module *Files) Lock(ctx context.Context, entry *FileEntry, expiresAt time.Time, preventDownload bool) (*FileLock, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	type lock struct {
+		Access              string     `json:"access"`
+		ExpiresAt           *core.Time `json:"expires_at,omitempty"`
+		IsDownloadPrevented bool       `json:"is_download_prevented"`
+	}
+	lockURL, _ := module.api.Parse(entry.ID)
+	result := FileEntry{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:     http.MethodPut,
+		URL:        lockURL,
+		Parameters: map[string]string{"fields": "lock"},
+		Payload: struct {
+			Lock lock `json:"lock"`
+		}{lock{"lock", optionalTime(expiresAt), preventDownload}},
+	}, &result); err != nil {
+		return nil, err
+	}
+	if result.Lock == nil {
+		return nil, errors.NotFound.With("lock", entry.ID)
+	}
+	entry.Lock = result.Lock
+	return result.Lock, nil
+}
+
+// Unlock removes the lock of a file, entry.Lock is cleared
+func (module *Files) Unlock(ctx context.Context, entry *FileEntry) error {
+	if entry == nil || len(entry.ID) == 0 {
+		return errors.ArgumentMissing.With("entry")
+	}
+	if !module.Client.IsAuthenticated() {
+		return errors.Unauthorized.WithStack()
+	}
+
+	unlockURL, _ := module.api.Parse(entry.ID)
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:     http.MethodPut,
+		URL:        unlockURL,
+		Parameters: map[string]string{"fields": "lock"},
+		Payload: struct {
+			Lock *FileLock `json:"lock"`
+		}{nil},
+	}, nil); err != nil {
+		return err
+	}
+	entry.Lock = nil
+	return nil
+}
+
+// MarshalJSON marshals this into JSON
+func (lock FileLock) MarshalJSON() ([]byte, error) {
+	type surrogate FileLock
+	data, err := json.Marshal(struct {
+		surrogate
+		CA *core.Time `json:"created_at,omitempty"`
+		EA *core.Time `json:"expired_at,omitempty"`
+	}{
+		surrogate: surrogate(lock),
+		CA:        optionalTime(lock.CreatedAt),
+		EA:        optionalTime(lock.ExpiresAt),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON decodes JSON
+func (lock *FileLock) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate FileLock
+	var inner struct {
+		surrogate
+		CA *core.Time `json:"created_at"`
+		EA *core.Time `json:"expired_at"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	*lock = FileLock(inner.surrogate)
+	lock.CreatedAt = timeOrZero(inner.CA)
+	lock.ExpiresAt = timeOrZero(inner.EA)
+	return
+}
@@ -0,0 +1,184 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type LockSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Mutex     sync.Mutex
+	// Lock is the lock of the file on the fake server
+	Lock map[string]interface{}
+	// Fields are the fields requested by the last GET
+	Fields string
+}
+
+func TestLockSuite(t *testing.T) {
+	suite.Run(t, new(LockSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *LockSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *LockSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *LockSuite) BeforeTest(suiteName, testName string) {
+	suite.Mutex.Lock()
+	suite.Lock = nil
+	suite.Fields = ""
+	suite.Mutex.Unlock()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *LockSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *LockSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		suite.Mutex.Lock()
+		defer suite.Mutex.Unlock()
+		if req.URL.Path != "/2.0/files/1234" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodGet:
+			suite.Fields = req.URL.Query().Get("fields")
+		case http.MethodPut:
+			var body struct {
+				Lock *struct {
+					Access              string `json:"access"`
+					ExpiresAt           string `json:"expires_at"`
+					IsDownloadPrevented bool   `json:"is_download_prevented"`
+				} `json:"lock"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			suite.Lock = nil
+			if body.Lock != nil {
+				suite.Assert().Equal("lock", body.Lock.Access)
+				suite.Lock = map[string]interface{}{
+					"type":                  "lock",
+					"id":                    "5678",
+					"is_download_prevented": body.Lock.IsDownloadPrevented,
+					"created_by":            map[string]string{"type": "user", "id": "42", "name": "John Doe"},
+					"created_at":            "2024-01-02T03:04:05Z",
+				}
+				if len(body.Lock.ExpiresAt) > 0 {
+					suite.Lock["expired_at"] = body.Lock.ExpiresAt
+				}
+			}
+		default:
+			res.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		entry := map[string]interface{}{"type": "file", "id": "1234", "etag": "1", "name": "hello.txt"}
+		if strings.Contains(req.URL.Query().Get("fields"), "lock") {
+			entry["lock"] = suite.Lock
+		}
+		res.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(res).Encode(entry)
+	}))
+}
+
+func (suite *LockSuite) TestCanLock() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	entry := &FileEntry{Type: "file", ID: "1234"}
+	expiresAt := time.Date(2100, time.January, 2, 3, 4, 5, 0, time.UTC)
+	lock, err := client.Files.Lock(context.Background(), entry, expiresAt, true)
+	suite.Require().Nilf(err, "Failed locking file. Error: %s", err)
+	suite.Require().NotNil(lock)
+	suite.Assert().Equal("5678", lock.ID)
+	suite.Assert().True(lock.IsDownloadPrevented)
+	suite.Assert().True(expiresAt.Equal(lock.ExpiresAt), "Lock should expire at %s, not %s", expiresAt, lock.ExpiresAt)
+	suite.Assert().Same(lock, entry.Lock, "The lock should be set in the entry")
+}
+
+func (suite *LockSuite) TestCanFindLockedFile() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.Lock(context.Background(), &FileEntry{Type: "file", ID: "1234"}, time.Time{}, false)
+	suite.Require().Nilf(err, "Failed locking file. Error: %s", err)
+
+	entry, err := client.Files.FindByID(context.Background(), "1234", &FindOptions{Fields: []string{"name", "etag", "lock"}})
+	suite.Require().Nilf(err, "Failed finding file. Error: %s", err)
+	suite.Assert().Equal("name,etag,lock", suite.Fields)
+	suite.Require().NotNil(entry.Lock, "The found entry should show the lock")
+	suite.Assert().Equal("5678", entry.Lock.ID)
+	suite.Assert().Equal("42", entry.Lock.CreatedBy.ID)
+	suite.Assert().False(entry.Lock.IsExpired(), "A lock without expiration should not expire")
+}
+
+func (suite *LockSuite) TestCanFindFileWithoutFields() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	entry, err := client.Files.FindByID(context.Background(), "1234")
+	suite.Require().Nilf(err, "Failed finding file. Error: %s", err)
+	suite.Assert().Empty(suite.Fields, "No fields should be requested")
+	suite.Assert().Equal("hello.txt", entry.Name)
+}
+
+func (suite *LockSuite) TestCanUnlock() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	entry := &FileEntry{Type: "file", ID: "1234"}
+	_, err := client.Files.Lock(context.Background(), entry, time.Time{}, false)
+	suite.Require().Nilf(err, "Failed locking file. Error: %s", err)
+	suite.Require().NotNil(entry.Lock)
+
+	err = client.Files.Unlock(context.Background(), entry)
+	suite.Require().Nilf(err, "Failed unlocking file. Error: %s", err)
+	suite.Assert().Nil(entry.Lock, "The lock should be cleared from the entry")
+
+	found, err := client.Files.FindByID(context.Background(), "1234", &FindOptions{Fields: []string{"lock"}})
+	suite.Require().Nilf(err, "Failed finding file. Error: %s", err)
+	suite.Assert().Nil(found.Lock, "The found entry should not have a lock")
+}
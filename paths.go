@@ -0,0 +1,261 @@
+package box
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// Paths module resolves items by their full path (e.g.: "/Clients/ACME/2024/report.pdf")
+//
+// The IDs of the resolved paths are cached, the cache is verified with the ETag and
+// the path collection of the resolved items and invalidated when they changed.
+type Paths struct {
+	*Client
+	mutex sync.Mutex
+	cache map[string]cachedPath
+}
+
+// cachedPath is the cached item of a path
+type cachedPath struct {
+	Type string
+	ID   string
+	ETag string
+}
+
+// Resolve finds the file or folder at the given path, starting from the root folder
+//
+// Paths are separated by "/", names are compared according to Client.CaseSensitive.
+// "/" or "" resolves to the root folder.
+//
+// If no item exists at the given path, NotFound is returned.
+func (module *Paths) Resolve(ctx context.Context, path string) (*Item, error) {
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+	segments := splitPath(path)
+	if len(segments) == 0 {
+		folder, err := module.Client.Folders.FindByID(ctx, "0")
+		if err != nil {
+			return nil, err
+		}
+		return &Item{Type: "folder", Folder: folder}, nil
+	}
+
+	item, fromCache, err := module.resolve(ctx, segments, true)
+	if err != nil {
+		return nil, err
+	}
+	if fromCache && item == nil {
+		// The cache was outdated, as any folder of the path may have been renamed or moved,
+		// the cached paths starting with the same top folder are forgotten and the path is walked again from the root folder
+		module.invalidate(module.cacheKey(segments[:1]))
+		item, _, err = module.resolve(ctx, segments, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if item == nil {
+		return nil, errors.NotFound.With("path", path)
+	}
+	return item, nil
+}
+
+// ResolveFile finds the file at the given path
+//
+// If the path is not a file, NotFound is returned.
+func (module *Paths) ResolveFile(ctx context.Context, path string) (*FileEntry, error) {
+	item, err := module.Resolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if item.File == nil {
+		return nil, errors.NotFound.With("file", path)
+	}
+	return item.File, nil
+}
+
+// ResolveFolder finds the folder at the given path
+//
+// If the path is not a folder, NotFound is returned.
+func (module *Paths) ResolveFolder(ctx context.Context, path string) (*FolderEntry, error) {
+	item, err := module.Resolve(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if item.Folder == nil {
+		return nil, errors.NotFound.With("folder", path)
+	}
+	return item.Folder, nil
+}
+
+// Forget removes the given path and all the paths below it from the cache
+func (module *Paths) Forget(path string) {
+	module.invalidate(module.cacheKey(splitPath(path)))
+}
+
+// resolve walks the given path segments from the root folder
+//
+// If useCache is true, cached IDs are used for the path and its parents. The resolved item is then verified
+// and nil is returned with fromCache set to true if the cache was outdated.
+func (module *Paths) resolve(ctx context.Context, segments []string, useCache bool) (item *Item, fromCache bool, err error) {
+	key := ""
+	current := cachedPath{Type: "folder", ID: "0"}
+	cachedLast := false
+	for index := range segments {
+		key = module.cacheKey(segments[:index+1])
+		if cached, found := module.lookup(key); found && useCache {
+			fromCache, cachedLast = true, true
+			current = cached
+			continue
+		}
+		cachedLast = false
+		if current.Type != "folder" {
+			return nil, fromCache, nil
+		}
+		child, found, err := module.findChild(ctx, current.ID, segments[index])
+		if err != nil {
+			if fromCache && errors.Is(err, errors.NotFound) {
+				return nil, true, nil
+			}
+			return nil, fromCache, err
+		}
+		if !found {
+			return nil, fromCache, nil
+		}
+		module.store(key, child)
+		current = child
+	}
+
+	var name, etag string
+	var paths PathCollection
+	switch current.Type {
+	case "file":
+		var entry *FileEntry
+		if entry, err = module.Client.Files.FindByID(ctx, current.ID); err == nil {
+			item = &Item{Type: "file", File: entry}
+			name, etag, paths = entry.Name, entry.ETag, entry.Paths
+		}
+	case "folder":
+		var entry *FolderEntry
+		if entry, err = module.Client.Folders.FindByID(ctx, current.ID); err == nil {
+			item = &Item{Type: "folder", Folder: entry}
+			name, etag, paths = entry.Name, entry.ETag, entry.Paths
+		}
+	default:
+		return nil, fromCache, nil
+	}
+	if err != nil {
+		if fromCache && errors.Is(err, errors.NotFound) {
+			return nil, true, nil
+		}
+		return nil, fromCache, err
+	}
+	// A renamed, moved or modified item has a new ETag, a renamed or moved parent changes the path collection
+	if (cachedLast && etag != current.ETag) || !module.matches(segments, name, paths) {
+		return nil, fromCache, nil
+	}
+	current.ETag = etag
+	module.store(key, current)
+	return item, fromCache, nil
+}
+
+// findChild finds the file or folder with the given name in a folder
+func (module *Paths) findChild(ctx context.Context, folderID, name string) (cachedPath, bool, error) {
+	items := module.Client.Folders.Items(ctx, &PathEntry{Type: "folder", ID: folderID}, &ItemsOptions{Fields: []string{"type", "id", "name", "etag"}})
+	for items.Next() {
+		item := items.Item()
+		if module.Client.sameName(item.Name(), name) {
+			switch {
+			case item.File != nil:
+				return cachedPath{Type: "file", ID: item.File.ID, ETag: item.File.ETag}, true, nil
+			case item.Folder != nil:
+				return cachedPath{Type: "folder", ID: item.Folder.ID, ETag: item.Folder.ETag}, true, nil
+			}
+		}
+	}
+	return cachedPath{}, false, items.Err()
+}
+
+// matches tells if the name and path collection of an item match the given path segments
+func (module *Paths) matches(segments []string, name string, paths PathCollection) bool {
+	if !module.Client.sameName(name, segments[len(segments)-1]) {
+		return false
+	}
+	ancestors := paths.Paths
+	if len(ancestors) > 0 && ancestors[0].ID == "0" {
+		ancestors = ancestors[1:]
+	}
+	if len(ancestors) != len(segments)-1 {
+		return false
+	}
+	for index, ancestor := range ancestors {
+		if !module.Client.sameName(ancestor.Name, segments[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheKey gives the key of the given path segments in the cache
+func (module *Paths) cacheKey(segments []string) string {
+	return module.Client.nameKey("/" + strings.Join(segments, "/"))
+}
+
+// lookup gets a path from the cache
+func (module *Paths) lookup(key string) (cachedPath, bool) {
+	module.mutex.Lock()
+	defer module.mutex.Unlock()
+	cached, found := module.cache[key]
+	return cached, found
+}
+
+// store stores a path in the cache
+func (module *Paths) store(key string, cached cachedPath) {
+	module.mutex.Lock()
+	defer module.mutex.Unlock()
+	if module.cache == nil {
+		module.cache = map[string]cachedPath{}
+	}
+	module.cache[key] = cached
+}
+
+// invalidate removes the given cache key and all the keys below it from the cache
+func (module *Paths) invalidate(prefix string) {
+	module.mutex.Lock()
+	defer module.mutex.Unlock()
+	for key := range module.cache {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") || prefix == "/" {
+			delete(module.cache, key)
+		}
+	}
+}
+
+// sameName tells if 2 item names are the same according to Client.CaseSensitive
+func (client *Client) sameName(name, other string) bool {
+	if client.CaseSensitive {
+		return name == other
+	}
+	return strings.EqualFold(name, other)
+}
+
+// nameKey gives the key of an item name in a map according to Client.CaseSensitive
+func (client *Client) nameKey(name string) string {
+	if !client.CaseSensitive {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// splitPath splits a path in its non empty segments
+func splitPath(path string) []string {
+	segments := []string{}
+	for _, segment := range strings.Split(path, "/") {
+		if len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
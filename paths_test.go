@@ -0,0 +1,310 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type PathsSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Tree      *fakeTree
+}
+
+func TestPathsSuite(t *testing.T) {
+	suite.Run(t, new(PathsSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *PathsSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Tree = &fakeTree{}
+	suite.Server = httptest.NewServer(suite.Tree)
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *PathsSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *PathsSuite) BeforeTest(suiteName, testName string) {
+	suite.Tree.Reset()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *PathsSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+// fakeNode is a folder or a file of a fakeTree
+type fakeNode struct {
+	Type   string
+	ID     string
+	Name   string
+	ETag   string
+	Parent string
+}
+
+// fakeTree serves a tree of folders and files as Box.com would
+type fakeTree struct {
+	Requests atomic.Int32
+	Mutex    sync.Mutex
+	Nodes    map[string]*fakeNode
+	Reserved int  // the number of folder creations to refuse with name_temporarily_reserved
+	CopyLost bool // tells to lose the answer of folder copies
+}
+
+// Reset resets the tree to /Clients/ACME/report.pdf
+func (tree *fakeTree) Reset() {
+	tree.Mutex.Lock()
+	tree.Nodes = map[string]*fakeNode{
+		"0": {Type: "folder", ID: "0", Name: "All Files", ETag: "0"},
+		"1": {Type: "folder", ID: "1", Name: "Clients", ETag: "0", Parent: "0"},
+		"2": {Type: "folder", ID: "2", Name: "ACME", ETag: "0", Parent: "1"},
+		"3": {Type: "file", ID: "3", Name: "report.pdf", ETag: "0", Parent: "2"},
+	}
+	tree.Reserved = 0
+	tree.CopyLost = false
+	tree.Mutex.Unlock()
+	tree.Requests.Store(0)
+}
+
+// Rename renames a node, which changes its ETag
+func (tree *fakeTree) Rename(id, name string) {
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+	tree.Nodes[id].Name = name
+	tree.Nodes[id].ETag = tree.Nodes[id].ETag + "1"
+}
+
+// ServeHTTP implements http.Handler
+func (tree *fakeTree) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Authorization") != "Bearer 1234" {
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	tree.Requests.Add(1)
+	tree.Mutex.Lock()
+	defer tree.Mutex.Unlock()
+
+	mini := func(node *fakeNode) map[string]interface{} {
+		return map[string]interface{}{"type": node.Type, "id": node.ID, "name": node.Name, "etag": node.ETag}
+	}
+	full := func(node *fakeNode) map[string]interface{} {
+		ancestors := []map[string]interface{}{}
+		for parent := node.Parent; len(parent) > 0; parent = tree.Nodes[parent].Parent {
+			ancestors = append([]map[string]interface{}{mini(tree.Nodes[parent])}, ancestors...)
+		}
+		entry := mini(node)
+		entry["path_collection"] = map[string]interface{}{"total_count": len(ancestors), "entries": ancestors}
+		return entry
+	}
+
+	var payload interface{}
+	segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/2.0/"), "/")
+	switch {
+	case req.Method == http.MethodPost && strings.TrimSuffix(req.URL.Path, "/") == "/2.0/folders":
+		var body struct {
+			Name   string `json:"name"`
+			Parent struct {
+				ID string `json:"id"`
+			} `json:"parent"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if tree.Reserved > 0 {
+			tree.Reserved--
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusConflict)
+			_, _ = res.Write([]byte(`{"type":"error","status":409,"code":"name_temporarily_reserved","message":"reserved"}`))
+			return
+		}
+		for _, node := range tree.Nodes {
+			if node.Parent == body.Parent.ID && strings.EqualFold(node.Name, body.Name) {
+				res.Header().Set("Content-Type", "application/json")
+				res.WriteHeader(http.StatusConflict)
+				_ = json.NewEncoder(res).Encode(map[string]interface{}{
+					"type": "error", "status": 409, "code": "item_name_in_use", "message": "Item with the same name already exists",
+					"context_info": map[string]interface{}{"conflicts": []interface{}{mini(node)}},
+				})
+				return
+			}
+		}
+		node := &fakeNode{Type: "folder", ID: fmt.Sprintf("%d", 100+len(tree.Nodes)), Name: body.Name, ETag: "0", Parent: body.Parent.ID}
+		tree.Nodes[node.ID] = node
+		res.WriteHeader(http.StatusCreated)
+		payload = full(node)
+	case req.Method == http.MethodPost && len(segments) == 3 && segments[0] == "folders" && segments[2] == "copy":
+		var body struct {
+			Name   string `json:"name"`
+			Parent struct {
+				ID string `json:"id"`
+			} `json:"parent"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		source, found := tree.Nodes[segments[1]]
+		if !found {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if len(body.Name) == 0 {
+			body.Name = source.Name
+		}
+		node := &fakeNode{Type: "folder", ID: fmt.Sprintf("%d", 100+len(tree.Nodes)), Name: body.Name, ETag: "0", Parent: body.Parent.ID}
+		tree.Nodes[node.ID] = node
+		if tree.CopyLost {
+			res.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		res.WriteHeader(http.StatusCreated)
+		payload = full(node)
+	case len(segments) == 3 && segments[0] == "folders" && segments[2] == "items":
+		entries := []map[string]interface{}{}
+		for _, node := range tree.Nodes {
+			if node.Parent == segments[1] {
+				entries = append(entries, mini(node))
+			}
+		}
+		payload = map[string]interface{}{"entries": entries, "limit": 1000, "next_marker": ""}
+	case len(segments) == 2 && (segments[0] == "folders" || segments[0] == "files"):
+		node, found := tree.Nodes[segments[1]]
+		if !found || node.Type+"s" != segments[0] {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		payload = full(node)
+	default:
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+	res.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(res).Encode(payload)
+}
+
+func (suite *PathsSuite) TestCanResolveFile() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	file, err := client.Paths.ResolveFile(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal("3", file.ID)
+	suite.Assert().Equal(int32(4), suite.Tree.Requests.Load(), "Each folder of the path should have been listed")
+}
+
+func (suite *PathsSuite) TestCanResolveFolder() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	folder, err := client.Paths.ResolveFolder(context.Background(), "Clients/ACME/")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal("2", folder.ID)
+
+	root, err := client.Paths.ResolveFolder(context.Background(), "/")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal("0", root.ID)
+}
+
+func (suite *PathsSuite) TestCanResolveFromCache() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Paths.Resolve(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+
+	suite.Tree.Requests.Store(0)
+	item, err := client.Paths.Resolve(context.Background(), "/clients/acme/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Require().NotNil(item.File)
+	suite.Assert().Equal("3", item.File.ID)
+	suite.Assert().Equal(int32(1), suite.Tree.Requests.Load(), "Only the file should have been fetched")
+}
+
+func (suite *PathsSuite) TestShouldInvalidateCacheWhenParentIsRenamed() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Paths.Resolve(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+
+	suite.Tree.Rename("2", "ACME Corp")
+	_, err = client.Paths.Resolve(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().NotNil(err, "Should have failed resolving path")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Error should be a Not Found Error. Error: %v", err)
+
+	file, err := client.Paths.ResolveFile(context.Background(), "/Clients/ACME Corp/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal("3", file.ID)
+}
+
+func (suite *PathsSuite) TestShouldInvalidateCacheWhenETagChanges() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Paths.Resolve(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+
+	suite.Tree.Rename("3", "report.pdf")
+	suite.Tree.Requests.Store(0)
+	file, err := client.Paths.ResolveFile(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().Nilf(err, "Failed resolving path. Error: %s", err)
+	suite.Assert().Equal("01", file.ETag)
+	suite.Assert().Equal(int32(5), suite.Tree.Requests.Load(), "The path should have been walked again")
+}
+
+func (suite *PathsSuite) TestShouldFailResolvingWithCaseSensitiveNames() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	client.CaseSensitive = true
+	_, err := client.Paths.Resolve(context.Background(), "/clients/ACME/report.pdf")
+	suite.Require().NotNil(err, "Should have failed resolving path")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Error should be a Not Found Error. Error: %v", err)
+}
+
+func (suite *PathsSuite) TestShouldFailResolvingFileAsFolder() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Paths.ResolveFolder(context.Background(), "/Clients/ACME/report.pdf")
+	suite.Require().NotNil(err, "Should have failed resolving path")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Error should be a Not Found Error. Error: %v", err)
+
+	_, err = client.Paths.Resolve(context.Background(), "/Clients/ACME/report.pdf/more")
+	suite.Require().NotNil(err, "Should have failed resolving path")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Error should be a Not Found Error. Error: %v", err)
+}
@@ -0,0 +1,128 @@
+package box
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-request"
+)
+
+// ProgressInterval is the minimum delay between 2 progress reports
+const ProgressInterval = 250 * time.Millisecond
+
+// Progress describes the progress of a transfer
+type Progress struct {
+	// Transferred is the number of bytes transferred so far
+	Transferred int64
+	// Total is the number of bytes to transfer, -1 if unknown
+	Total int64
+	// Rate is the average transfer rate in bytes per second
+	Rate float64
+	// Elapsed is the time since the transfer started
+	Elapsed time.Duration
+	// Done tells if the transfer finished successfully
+	Done bool
+}
+
+// ProgressFunc is called as a transfer progresses
+//
+// It is called at most every ProgressInterval and once more when the transfer ends, with Done set only if it succeeded
+type ProgressFunc func(progress Progress)
+
+// Percent gives the percentage of the transfer that is done, -1 if the total is unknown
+func (progress Progress) Percent() float64 {
+	if progress.Total < 0 {
+		return -1
+	}
+	if progress.Total == 0 {
+		return 100
+	}
+	return float64(progress.Transferred) * 100 / float64(progress.Total)
+}
+
+// progressTracker tracks the data written to it and reports the progress to a writer and/or a function
+//
+// It is safe for concurrent use.
+type progressTracker struct {
+	writer      io.Writer
+	report      ProgressFunc
+	total       int64
+	transferred int64
+	start       time.Time
+	last        time.Time
+	done        bool
+	finished    bool
+	mutex       sync.Mutex
+}
+
+// newProgressTracker creates a progressTracker for a transfer of total bytes
+//
+// If the writer implements request.ProgressBarMaxSetter or request.ProgressBarMaxChanger, its maximum is set to total.
+//
+// If both the writer and the report function are nil, newProgressTracker returns nil.
+func newProgressTracker(writer io.Writer, report ProgressFunc, total int64) *progressTracker {
+	if writer == nil && report == nil {
+		return nil
+	}
+	if writer != nil && total >= 0 {
+		if setter, ok := writer.(request.ProgressBarMaxSetter); ok {
+			setter.SetMax64(total)
+		} else if changer, ok := writer.(request.ProgressBarMaxChanger); ok {
+			changer.ChangeMax64(total)
+		}
+	}
+	now := time.Now()
+	return &progressTracker{writer: writer, report: report, total: total, start: now, last: now}
+}
+
+// Write tracks the given data
+//
+// implements io.Writer
+func (tracker *progressTracker) Write(data []byte) (int, error) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.writer != nil {
+		_, _ = tracker.writer.Write(data)
+	}
+	tracker.transferred += int64(len(data))
+	if tracker.report != nil && time.Since(tracker.last) >= ProgressInterval {
+		tracker.last = time.Now()
+		tracker.report(tracker.progress())
+	}
+	return len(data), nil
+}
+
+// finish reports the final progress of a transfer that ended with the given error
+func (tracker *progressTracker) finish(err error) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if !tracker.finished {
+		tracker.finished = true
+		tracker.done = err == nil
+		if tracker.report != nil {
+			tracker.report(tracker.progress())
+		}
+	}
+}
+
+// restart forgets the data tracked so far, when the transfer is started again
+func (tracker *progressTracker) restart() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.transferred = 0
+}
+
+func (tracker *progressTracker) progress() Progress {
+	elapsed := time.Since(tracker.start)
+	progress := Progress{
+		Transferred: tracker.transferred,
+		Total:       tracker.total,
+		Elapsed:     elapsed,
+		Done:        tracker.done,
+	}
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		progress.Rate = float64(tracker.transferred) / seconds
+	}
+	return progress
+}
@@ -0,0 +1,182 @@
+package box
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// Representations module
+type Representations struct {
+	*Client
+	api *url.URL
+}
+
+// Representation represents a representation of a file (PDF, PNG pages, extracted text, etc)
+type Representation struct {
+	Representation string                `json:"representation"`
+	Properties     map[string]string     `json:"properties,omitempty"`
+	Info           RepresentationInfo    `json:"info"`
+	Status         RepresentationStatus  `json:"status"`
+	Content        RepresentationContent `json:"content"`
+}
+
+// RepresentationInfo gives the URL to fetch the current state of a Representation
+type RepresentationInfo struct {
+	URL string `json:"url"`
+}
+
+// RepresentationStatus gives the state of a Representation (none, pending, success, error)
+type RepresentationStatus struct {
+	State string `json:"state"`
+}
+
+// RepresentationContent gives the URL template to download the assets of a Representation
+type RepresentationContent struct {
+	URLTemplate string `json:"url_template"`
+}
+
+// RepresentationPollInterval is the delay between 2 checks of a pending Representation
+const RepresentationPollInterval = 1 * time.Second
+
+// Thumbnail downloads the thumbnail of a file
+//
+// format is either "png" or "jpg", minSize is the minimum width and height of the thumbnail (0 for Box.com's default).
+//
+// If the thumbnail is being generated, Thumbnail waits for it as instructed by Box.com, until the context is done.
+// If Box.com cannot generate a thumbnail, it gives a placeholder icon instead.
+func (module *Files) Thumbnail(ctx context.Context, entry *FileEntry, format string, minSize int) (*request.Content, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if format != "png" && format != "jpg" {
+		return nil, errors.ArgumentInvalid.With("format", format)
+	}
+	if minSize < 0 {
+		return nil, errors.ArgumentInvalid.With("minSize", minSize)
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	thumbnailURL, _ := module.api.Parse(entry.ID + "/thumbnail." + format)
+	if minSize > 0 {
+		size := strconv.Itoa(minSize)
+		thumbnailURL.RawQuery = url.Values{"min_width": []string{size}, "min_height": []string{size}}.Encode()
+	}
+	return module.Client.downloadContent(ctx, thumbnailURL)
+}
+
+// List lists the representations of a file that match the given hints
+//
+// hints follows the X-Rep-Hints syntax of Box.com, e.g.: "[pdf][png?dimensions=1024x1024][extracted_text]".
+//
+// If Box.com cannot give any of the requested representations, RequestedPreviewUnavailable is returned.
+func (module *Representations) List(ctx context.Context, entry *FileEntry, hints string) ([]Representation, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if len(hints) == 0 {
+		return nil, errors.ArgumentMissing.With("hints")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	listURL, _ := module.api.Parse(entry.ID)
+	result := struct {
+		Representations struct {
+			Entries []Representation `json:"entries"`
+		} `json:"representations"`
+	}{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL:        listURL,
+		Headers:    map[string]string{"X-Rep-Hints": hints},
+		Parameters: map[string]string{"fields": "representations"},
+	}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Representations.Entries) == 0 {
+		return nil, errors.WithStack(RequestedPreviewUnavailable)
+	}
+	return result.Representations.Entries, nil
+}
+
+// Wait waits until the given representation is generated
+//
+// Box.com generates representations on demand, the representation is checked every RepresentationPollInterval until it is ready or the context is done.
+//
+// If Box.com fails generating the representation, PreviewCannotBeGenerated is returned.
+func (module *Representations) Wait(ctx context.Context, representation *Representation) (*Representation, error) {
+	if representation == nil {
+		return nil, errors.ArgumentMissing.With("representation")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+	current := *representation
+	for attempt := 0; ; attempt++ {
+		switch current.Status.State {
+		case "success", "viewable":
+			return &current, nil
+		case "error":
+			return nil, errors.WithStack(PreviewCannotBeGenerated)
+		}
+		if len(current.Info.URL) == 0 {
+			return nil, errors.ArgumentMissing.With("info.url")
+		}
+		infoURL, err := url.Parse(current.Info.URL)
+		if err != nil {
+			return nil, errors.ArgumentInvalid.With("info.url", current.Info.URL)
+		}
+		// Fetching the info of a representation in the "none" state triggers its generation, no need to wait the first time
+		if attempt > 0 || current.Status.State != "none" {
+			select {
+			case <-ctx.Done():
+				return nil, errors.WithStack(ctx.Err())
+			case <-time.After(RepresentationPollInterval):
+			}
+		}
+		if _, err := module.Client.sendRequest(ctx, &request.Options{URL: infoURL}, &current); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Download downloads an asset of the given representation, waiting for the representation to be generated if needed
+//
+// assetPath is the asset to download in the representation, e.g.: "1.png" for the first page of a png representation.
+// It is empty for representations with a single asset like "pdf" or "extracted_text".
+func (module *Representations) Download(ctx context.Context, representation *Representation, assetPath string) (*request.Content, error) {
+	ready, err := module.Wait(ctx, representation)
+	if err != nil {
+		return nil, err
+	}
+	if len(ready.Content.URLTemplate) == 0 {
+		return nil, errors.ArgumentMissing.With("content.url_template")
+	}
+	contentURL, err := url.Parse(strings.Replace(ready.Content.URLTemplate, "{+asset_path}", assetPath, 1))
+	if err != nil {
+		return nil, errors.ArgumentInvalid.With("content.url_template", ready.Content.URLTemplate)
+	}
+	return module.Client.downloadContent(ctx, contentURL)
+}
+
+// downloadContent downloads the content at the given URL in memory
+func (client *Client) downloadContent(ctx context.Context, contentURL *url.URL) (*request.Content, error) {
+	res, err := client.sendContentRequest(ctx, contentURL, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	content, err := request.ContentFromReader(res.Body, res.Header.Get("Content-Type"), res.Header)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return content, nil
+}
@@ -0,0 +1,206 @@
+package box
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type RepresentationSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Polls     atomic.Int32
+}
+
+func TestRepresentationSuite(t *testing.T) {
+	suite.Run(t, new(RepresentationSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *RepresentationSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *RepresentationSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *RepresentationSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *RepresentationSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *RepresentationSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		server := "http://" + req.Host
+		switch req.URL.Path {
+		case "/2.0/files/1234":
+			if req.URL.Query().Get("fields") == "expiring_embed_link" {
+				res.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(res, `{"type":"file","id":"1234","expiring_embed_link":{"url":"%s/preview/1234","token":{"access_token":"abcd","expires_in":3600,"token_type":"bearer"}}}`, server)
+				return
+			}
+			suite.Assert().Equal("representations", req.URL.Query().Get("fields"))
+			suite.Assert().Equal("[pdf][extracted_text]", req.Header.Get("X-Rep-Hints"))
+			res.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(res, `{"type":"file","id":"1234","representations":{"entries":[`+
+				`{"representation":"pdf","properties":{},"info":{"url":"%[1]s/2.0/internal_files/1234/versions/5678/representations/pdf"},"status":{"state":"none"},"content":{"url_template":""}},`+
+				`{"representation":"extracted_text","info":{"url":"%[1]s/2.0/internal_files/1234/versions/5678/representations/extracted_text"},"status":{"state":"success"},"content":{"url_template":"%[1]s/content/text/{+asset_path}"}}`+
+				`]}}`, server)
+		case "/2.0/files/4321":
+			res.Header().Set("Content-Type", "application/json")
+			if req.URL.Query().Get("fields") == "expiring_embed_link" {
+				_, _ = res.Write([]byte(`{"type":"file","id":"4321","expiring_embed_link":null}`))
+				return
+			}
+			_, _ = res.Write([]byte(`{"type":"file","id":"4321","representations":{"entries":[]}}`))
+		case "/2.0/internal_files/1234/versions/5678/representations/pdf":
+			res.Header().Set("Content-Type", "application/json")
+			if suite.Polls.Add(1) == 1 {
+				fmt.Fprintf(res, `{"representation":"pdf","info":{"url":"%s%s"},"status":{"state":"pending"}}`, server, req.URL.Path)
+				return
+			}
+			fmt.Fprintf(res, `{"representation":"pdf","info":{"url":"%s%s"},"status":{"state":"success"},"content":{"url_template":"%s/content/pdf/{+asset_path}"}}`, server, req.URL.Path, server)
+		case "/2.0/internal_files/9999/versions/5678/representations/pdf":
+			res.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(res, `{"representation":"pdf","info":{"url":"%s%s"},"status":{"state":"error"}}`, server, req.URL.Path)
+		case "/content/pdf/":
+			res.Header().Set("Content-Type", "application/pdf")
+			_, _ = res.Write([]byte("%PDF-1.4"))
+		case "/content/text/":
+			res.Header().Set("Content-Type", "text/plain")
+			_, _ = res.Write([]byte("Hello, World!"))
+		case "/2.0/files/1234/thumbnail.png":
+			suite.Assert().Equal("64", req.URL.Query().Get("min_width"))
+			suite.Assert().Equal("64", req.URL.Query().Get("min_height"))
+			res.Header().Set("Content-Type", "image/png")
+			_, _ = res.Write([]byte("\x89PNG"))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *RepresentationSuite) TestCanDownloadThumbnail() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	content, err := client.Files.Thumbnail(context.Background(), &FileEntry{ID: "1234"}, "png", 64)
+	suite.Require().Nilf(err, "Failed downloading thumbnail. Error: %s", err)
+	suite.Assert().Equal("image/png", content.Type)
+	suite.Assert().Equal([]byte("\x89PNG"), content.Data)
+}
+
+func (suite *RepresentationSuite) TestShouldFailDownloadingThumbnailWithInvalidFormat() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.Thumbnail(context.Background(), &FileEntry{ID: "1234"}, "gif", 64)
+	suite.Require().NotNil(err, "Should have failed downloading thumbnail")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an Argument Invalid Error. Error: %v", err)
+}
+
+func (suite *RepresentationSuite) TestCanListRepresentations() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	representations, err := client.Representations.List(context.Background(), &FileEntry{ID: "1234"}, "[pdf][extracted_text]")
+	suite.Require().Nilf(err, "Failed listing representations. Error: %s", err)
+	suite.Require().Len(representations, 2)
+	suite.Assert().Equal("pdf", representations[0].Representation)
+	suite.Assert().Equal("none", representations[0].Status.State)
+	suite.Assert().Equal("extracted_text", representations[1].Representation)
+}
+
+func (suite *RepresentationSuite) TestShouldFailListingUnavailableRepresentations() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Representations.List(context.Background(), &FileEntry{ID: "4321"}, "[pdf]")
+	suite.Require().NotNil(err, "Should have failed listing representations")
+	suite.Assert().Truef(errors.Is(err, RequestedPreviewUnavailable), "Error should be a Requested Preview Unavailable Error. Error: %v", err)
+}
+
+func (suite *RepresentationSuite) TestCanDownloadPendingRepresentation() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	representations, err := client.Representations.List(context.Background(), &FileEntry{ID: "1234"}, "[pdf][extracted_text]")
+	suite.Require().Nilf(err, "Failed listing representations. Error: %s", err)
+
+	suite.Polls.Store(0)
+	content, err := client.Representations.Download(context.Background(), &representations[0], "")
+	suite.Require().Nilf(err, "Failed downloading representation. Error: %s", err)
+	suite.Assert().Equal(int32(2), suite.Polls.Load(), "The representation should have been polled until ready")
+	suite.Assert().Equal("application/pdf", content.Type)
+	suite.Assert().Equal("%PDF-1.4", string(content.Data))
+
+	content, err = client.Representations.Download(context.Background(), &representations[1], "")
+	suite.Require().Nilf(err, "Failed downloading representation. Error: %s", err)
+	suite.Assert().Equal("Hello, World!", string(content.Data))
+}
+
+func (suite *RepresentationSuite) TestShouldFailWaitingForFailedRepresentation() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Representations.Wait(context.Background(), &Representation{
+		Representation: "pdf",
+		Info:           RepresentationInfo{URL: suite.Server.URL + "/2.0/internal_files/9999/versions/5678/representations/pdf"},
+		Status:         RepresentationStatus{State: "none"},
+	})
+	suite.Require().NotNil(err, "Should have failed waiting for representation")
+	suite.Assert().Truef(errors.Is(err, PreviewCannotBeGenerated), "Error should be a Preview Cannot Be Generated Error. Error: %v", err)
+}
+
+func (suite *RepresentationSuite) TestCanGetEmbedLink() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	link, err := client.Files.EmbedLink(context.Background(), &FileEntry{ID: "1234"})
+	suite.Require().Nilf(err, "Failed getting embed link. Error: %s", err)
+	suite.Require().NotNil(link.URL)
+	suite.Assert().Equal("/preview/1234", link.URL.Path)
+	suite.Assert().WithinDuration(time.Now().Add(time.Hour), link.ExpiresAt, 5*time.Second)
+	suite.Assert().False(link.IsExpired())
+}
+
+func (suite *RepresentationSuite) TestShouldFailGettingEmbedLinkOfUnpreviewableFile() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.EmbedLink(context.Background(), &FileEntry{ID: "4321"})
+	suite.Require().NotNil(err, "Should have failed getting embed link")
+	suite.Assert().Truef(errors.Is(err, RequestedPreviewUnavailable), "Error should be a Requested Preview Unavailable Error. Error: %v", err)
+}
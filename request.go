@@ -2,11 +2,27 @@ package box
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
 	"github.com/gildas/go-request"
+	"github.com/google/uuid"
 )
 
+// MaxRedirects is the maximum number of redirections followed when fetching content
+const MaxRedirects = 10
+
+// MaxContentRetries is the maximum number of times a content or upload request is sent again when Box.com asks to retry later
+const MaxContentRetries = 10
+
+// DefaultRetryAfter is the delay to wait when Box.com asks to retry later without a Retry-After header
+const DefaultRetryAfter = 1 * time.Second
+
 // sendRequest sends an HTTP request to Box.com's API
 func (client *Client) sendRequest(ctx context.Context, options *request.Options, results interface{}) (*request.Content, error) {
 	if options == nil {
@@ -25,29 +41,199 @@ func (client *Client) sendRequest(ctx context.Context, options *request.Options,
 	// boxRequestID := res.Header.Get("Box-Request-Id")
 
 	if err != nil {
+		return response, convertError(err, response)
+	}
+	return response, nil
+}
+
+// sendContentRequest sends a GET request for binary content to Box.com
+//
+// Redirections are followed explicitly and the Authorization header is only sent to the API host
+// and the host of contentURL, so it does not leak to the servers Box.com redirects to (e.g.: dl.boxcloud.com).
+// If authenticated is false, contentURL does not need the Authorization header and it is not sent at all.
+//
+// When Box.com answers with 202 Accepted or 429 Too Many Requests, the request is sent again after the Retry-After delay,
+// as long as the context allows it and at most MaxContentRetries times.
+//
+// The caller must close the body of the returned response.
+func (client *Client) sendContentRequest(ctx context.Context, contentURL *url.URL, headers map[string]string, authenticated bool) (*http.Response, error) {
+	log := client.Logger.Child(nil, "content")
+	httpclient := client.httpClient()
+
+	currentURL := contentURL
+	for redirects, retries := 0, 0; ; {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, currentURL.String(), nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		req.Header.Set("User-Agent", "BOX Client "+VERSION)
+		req.Header.Set("X-Request-Id", uuid.Must(uuid.NewRandom()).String())
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		if authenticated && (currentURL.Host == client.Api.Host || currentURL.Host == contentURL.Host) && client.IsAuthenticated() {
+			req.Header.Set("Authorization", request.BearerAuthorization(client.Auth.Token.AccessToken))
+		}
+
+		log.Debugf("HTTP GET %s", currentURL.Host+currentURL.Path)
+		res, err := httpclient.Do(req)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		log.Debugf("Response %s", res.Status)
+
+		switch {
+		case res.StatusCode == http.StatusAccepted || res.StatusCode == http.StatusTooManyRequests:
+			res.Body.Close()
+			if retries++; retries > MaxContentRetries {
+				return nil, errors.HTTPStatusTooManyRequests.WithStack()
+			}
+			if err := waitRetryAfter(ctx, res, log); err != nil {
+				return nil, err
+			}
+		case res.StatusCode >= 300 && res.StatusCode < 400:
+			location, err := res.Location()
+			res.Body.Close()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if redirects++; redirects > MaxRedirects {
+				return nil, errors.Errorf("Stopped after %d redirects", MaxRedirects)
+			}
+			log.Tracef("Following redirect to %s", location.Host+location.Path)
+			currentURL = location
+		case res.StatusCode >= 400:
+			defer res.Body.Close()
+			content, _ := request.ContentFromReader(res.Body, res.Header.Get("Content-Type"), res.Header)
+			return nil, convertError(errors.FromHTTPStatusCode(res.StatusCode), content)
+		default:
+			return res, nil
+		}
+	}
+}
+
+// sendUploadRequest sends a POST request with the given body to Box.com and decodes the results
+//
+// Unlike sendRequest, the body is streamed and not kept in memory.
+// If length is -1, the body is sent in chunks.
+//
+// When Box.com answers with 429 Too Many Requests or a 5xx status and rewind is not nil, rewind gives the body to send again
+// after the Retry-After delay, as long as the context allows it and at most MaxContentRetries times.
+func (client *Client) sendUploadRequest(ctx context.Context, uploadURL *url.URL, headers map[string]string, body io.Reader, length int64, rewind func() (io.Reader, error), results interface{}) error {
+	log := client.Logger.Child(nil, "upload")
+	for retries := 0; ; retries++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL.String(), body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		req.ContentLength = length
+		req.Header.Set("User-Agent", "BOX Client "+VERSION)
+		req.Header.Set("X-Request-Id", uuid.Must(uuid.NewRandom()).String())
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		if client.IsAuthenticated() {
+			req.Header.Set("Authorization", request.BearerAuthorization(client.Auth.Token.AccessToken))
+		}
+
+		log.Debugf("HTTP POST %s", uploadURL.Host+uploadURL.Path)
+		res, err := client.httpClient().Do(req)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		log.Debugf("Response %s", res.Status)
+
+		content, err := request.ContentFromReader(res.Body, res.Header.Get("Content-Type"), res.Header)
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		if (res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500) && rewind != nil && retries < MaxContentRetries {
+			if err := waitRetryAfter(ctx, res, log); err != nil {
+				return err
+			}
+			if body, err = rewind(); err != nil {
+				return err
+			}
+			continue
+		}
+		if res.StatusCode >= 300 {
+			return convertError(errors.FromHTTPStatusCode(res.StatusCode), content)
+		}
+		if results != nil {
+			return content.UnmarshalContentJSON(results)
+		}
+		return nil
+	}
+}
+
+// waitRetryAfter waits for the Retry-After delay of the given response, or DefaultRetryAfter if it has none
+//
+// It fails right away if the context would expire before the end of the delay.
+func waitRetryAfter(ctx context.Context, res *http.Response, log *logger.Logger) error {
+	retryAfter := DefaultRetryAfter
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+		return errors.HTTPStatusRequestTimeout.Wrap(context.DeadlineExceeded)
+	}
+	log.Infof("Box.com answered %s, waiting for %s before trying again", res.Status, retryAfter)
+	select {
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	case <-time.After(retryAfter):
+	}
+	return nil
+}
+
+// httpClient gives the HTTP client used for content requests
+//
+// It is created once per Client, so the connections are reused. It does not follow redirections.
+//
+// It is used for the requests go-request cannot handle, i.e. content downloads and streamed uploads.
+func (client *Client) httpClient() *http.Client {
+	client.httpclientOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if client.Proxy != nil {
+			transport.Proxy = http.ProxyURL(client.Proxy)
+		}
+		client.httpclient = &http.Client{
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	})
+	return client.httpclient
+}
+
+// convertError converts an HTTP error into a Box.com error if the response contains details
+func convertError(err error, response *request.Content) error {
+	if response != nil {
 		var details *RequestError
-		if jerr := response.UnmarshalContentJSON(&details); jerr == nil {
+		if jerr := response.UnmarshalContentJSON(&details); jerr == nil && details != nil {
 			var httperr *errors.Error
 			if errors.As(err, &httperr) {
 				details.StatusCode = httperr.Code
 			}
 			if errors.Is(err, errors.HTTPBadRequest) && errors.Is(details, InvalidGrant) {
-				return nil, errors.Unauthorized.Wrap(details)
+				return errors.Unauthorized.Wrap(details)
 			}
 			if errors.Is(err, errors.HTTPUnauthorized) {
-				return nil, errors.Unauthorized.Wrap(details)
+				return errors.Unauthorized.Wrap(details)
 			}
 			if errors.Is(err, errors.HTTPNotFound) {
-				return nil, errors.NotFound.Wrap(details)
+				return errors.NotFound.Wrap(details)
 			}
-			return nil, errors.WithStack(details)
-		}
-		if errors.Is(err, errors.HTTPUnauthorized) {
-			return nil, errors.Unauthorized.Wrap(err)
-		}
-		if errors.Is(err, errors.HTTPNotFound) {
-			return nil, errors.NotFound.Wrap(err)
+			return errors.WithStack(details)
 		}
 	}
-	return response, err
+	if errors.Is(err, errors.HTTPUnauthorized) {
+		return errors.Unauthorized.Wrap(err)
+	}
+	if errors.Is(err, errors.HTTPNotFound) {
+		return errors.NotFound.Wrap(err)
+	}
+	return err
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/gildas/go-logger"
 	"github.com/gildas/go-request"
 	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -27,6 +28,7 @@ type RequestSuite struct {
 
 	Server    *httptest.Server
 	ServerURL *url.URL
+	Attempts  int
 }
 
 func TestRequestSuite(t *testing.T) {
@@ -104,6 +106,31 @@ func (suite *RequestSuite) CreateServer() *httptest.Server {
 				res.WriteHeader(http.StatusUnauthorized)
 				payload, _ := json.Marshal(InvalidPrivateKey)
 				_, _ = res.Write(payload)
+			case "/content/accepted":
+				if suite.Attempts++; suite.Attempts == 1 {
+					res.Header().Set("Retry-After", "1")
+					res.WriteHeader(http.StatusAccepted)
+					return
+				}
+				res.Header().Set("Content-Type", "text/plain")
+				_, _ = res.Write([]byte("Hello, World!"))
+			case "/content/notready":
+				res.Header().Set("Retry-After", "10")
+				res.WriteHeader(http.StatusAccepted)
+			case "/content/toomany":
+				suite.Attempts++
+				res.Header().Set("Retry-After", "0")
+				res.WriteHeader(http.StatusTooManyRequests)
+			case "/content/redirect":
+				suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+				target := *suite.ServerURL
+				target.Host = strings.Replace(target.Host, "127.0.0.1", "localhost", 1)
+				target.Path = "/content/target"
+				http.Redirect(res, req, target.String(), http.StatusFound)
+			case "/content/target":
+				suite.Assert().Empty(req.Header.Get("Authorization"), "Authorization should not be sent to other hosts")
+				res.Header().Set("Content-Type", "text/plain")
+				_, _ = res.Write([]byte("Hello, World!"))
 			case "/unauthorized":
 				res.Header().Set("Content-Type", "text/plain")
 				res.WriteHeader(http.StatusUnauthorized)
@@ -125,6 +152,30 @@ func (suite *RequestSuite) CreateServer() *httptest.Server {
 	}))
 }
 
+func (suite *RequestSuite) CreateAuthenticatedClient() *Client {
+	client := NewClient(suite.Logger.ToContext(context.Background()))
+	suite.Require().NotNil(client)
+	client.Api = suite.ServerURL
+	client.Auth.Token = &Token{TokenType: "Bearer", AccessToken: "1234", ExpiresOn: time.Now().UTC().Add(1 * time.Hour)}
+	return client
+}
+
+// newTestClient creates a Client with a fake token whose modules send their requests to the given test server
+func newTestClient(t *testing.T, serverURL *url.URL, log *logger.Logger) *Client {
+	client := NewClient(log.ToContext(context.Background()))
+	require.NotNil(t, client)
+	client.Api, _ = serverURL.Parse("/2.0/")
+	client.UploadApi, _ = serverURL.Parse("/api/2.0/")
+	client.Files.api = client.moduleApi("files/")
+	client.FileVersions.api = client.moduleApi("files/")
+	client.Folders.api = client.moduleApi("folders/")
+	client.SharedLinks.api = client.moduleApi("files/")
+	client.Representations.api = client.moduleApi("files/")
+	client.ZipDownloads.api = client.moduleApi("zip_downloads")
+	client.Auth.Token = &Token{TokenType: "Bearer", AccessToken: "1234", ExpiresOn: time.Now().UTC().Add(1 * time.Hour)}
+	return client
+}
+
 func (suite *RequestSuite) TestShouldFailSendingWithoutOptions() {
 	client := NewClient(suite.Logger.ToContext(context.Background()))
 	suite.Require().NotNil(client)
@@ -267,6 +318,62 @@ func (suite *RequestSuite) TestShouldReceiveErrorWithDetails() {
 	suite.Require().True(errors.As(err, &details), "Error should be a RequestError")
 }
 
+func (suite *RequestSuite) TestCanSendContentRequestWhenAccepted() {
+	client := suite.CreateAuthenticatedClient()
+	reqURL, _ := suite.ServerURL.Parse("/content/accepted")
+	suite.Attempts = 0
+	res, err := client.sendContentRequest(context.Background(), reqURL, nil, true)
+	suite.Require().Nilf(err, "Failed sending request. Error: %s", err)
+	defer res.Body.Close()
+	suite.Assert().Equal(http.StatusOK, res.StatusCode)
+	suite.Assert().Equal(2, suite.Attempts, "The request should have been sent twice")
+}
+
+func (suite *RequestSuite) TestCanSendContentRequestWithRedirect() {
+	client := suite.CreateAuthenticatedClient()
+	reqURL, _ := suite.ServerURL.Parse("/content/redirect")
+	res, err := client.sendContentRequest(context.Background(), reqURL, nil, true)
+	suite.Require().Nilf(err, "Failed sending request. Error: %s", err)
+	defer res.Body.Close()
+	suite.Assert().Equal(http.StatusOK, res.StatusCode)
+	suite.Assert().Equal("localhost", res.Request.URL.Hostname())
+}
+
+func (suite *RequestSuite) TestShouldFailSendingContentRequestWhenNotReadyBeforeDeadline() {
+	client := suite.CreateAuthenticatedClient()
+	reqURL, _ := suite.ServerURL.Parse("/content/notready")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := client.sendContentRequest(ctx, reqURL, nil, true)
+	suite.Require().NotNil(err, "Should have failed sending request")
+	suite.Assert().Truef(errors.Is(err, errors.HTTPStatusRequestTimeout), "Errors should be a Request Timeout Error. Error: %v", err)
+}
+
+func (suite *RequestSuite) TestShouldFailSendingContentRequestAfterMaxRetries() {
+	client := suite.CreateAuthenticatedClient()
+	reqURL, _ := suite.ServerURL.Parse("/content/toomany")
+	suite.Attempts = 0
+	_, err := client.sendContentRequest(context.Background(), reqURL, nil, true)
+	suite.Require().NotNil(err, "Should have failed sending request")
+	suite.Assert().Truef(errors.Is(err, errors.HTTPStatusTooManyRequests), "Errors should be a Too Many Requests Error. Error: %v", err)
+	suite.Assert().Equal(MaxContentRetries+1, suite.Attempts)
+}
+
+func (suite *RequestSuite) TestCanReuseContentHTTPClient() {
+	client := suite.CreateAuthenticatedClient()
+	suite.Assert().Same(client.httpClient(), client.httpClient())
+}
+
+func (suite *RequestSuite) TestShouldReceiveErrorWithDetailsWhenSendingContentRequest() {
+	client := suite.CreateAuthenticatedClient()
+	reqURL, _ := suite.ServerURL.Parse("/details/notfound")
+	_, err := client.sendContentRequest(context.Background(), reqURL, nil, true)
+	suite.Require().NotNil(err, "Should have failed sending request")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Errors should be a Not Found Error. Error: %v", err)
+	var details *RequestError
+	suite.Require().True(errors.As(err, &details), "Error should be a RequestError")
+}
+
 func (suite *RequestSuite) TestCanMarshalRequestError() {
 	payload, err := json.Marshal(InvalidGrant)
 	suite.Require().Nilf(err, "Error should be nil. Error: %v", err)
@@ -280,3 +387,20 @@ func (suite *RequestSuite) TestRequestErrorImplementsIs() {
 	suite.Assert().False(errors.Is(err, FolderNotEmpty))
 	suite.Assert().False(errors.Is(err, errors.NotFound))
 }
+
+func (suite *RequestSuite) TestCanUnmarshalContextInfoConflicts() {
+	var single, multiple ContextInfo
+	err := json.Unmarshal([]byte(`{"conflicts":{"type":"file","id":"1234","name":"hello.txt"}}`), &single)
+	suite.Require().Nilf(err, "Failed unmarshaling ContextInfo. Error: %s", err)
+	suite.Require().Len(single.Conflicts, 1)
+	suite.Assert().Equal("1234", single.Conflicts[0].ID)
+
+	err = json.Unmarshal([]byte(`{"conflicts":[{"type":"folder","id":"1"},{"type":"folder","id":"2"}],"errors":[{"reason":"invalid"}]}`), &multiple)
+	suite.Require().Nilf(err, "Failed unmarshaling ContextInfo. Error: %s", err)
+	suite.Require().Len(multiple.Conflicts, 2)
+	suite.Assert().JSONEq(`[{"reason":"invalid"}]`, string(multiple.Errors))
+
+	payload, err := json.Marshal(multiple)
+	suite.Require().Nilf(err, "Failed marshaling ContextInfo. Error: %s", err)
+	suite.Assert().Contains(string(payload), `"errors":[{"reason":"invalid"}]`, "errors should be marshaled as JSON")
+}
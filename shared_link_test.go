@@ -58,7 +58,7 @@ func (suite *SharedLinkSuite) TearDownSuite() {
 		suite.Logger.Infof("All tests succeeded, we are cleaning")
 		folder, err := suite.Client.Folders.FindByName(context.Background(), "unit-test")
 		if err == nil {
-			err := suite.Client.Folders.Delete(context.Background(), folder)
+			err := suite.Client.Folders.Delete(context.Background(), folder, true)
 			suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		}
 	}
@@ -100,7 +100,7 @@ func (suite *SharedLinkSuite) AfterTest(suiteName, testName string) {
 			err := suite.Client.Auth.Authenticate(context.Background(), suite.FetchCredentials())
 			suite.Require().Nil(err, "Failed to authenticate box.Client")
 		}
-		err := suite.Client.Folders.Delete(context.Background(), suite.Root)
+		err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
 		suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
 		suite.Root = nil
 	}
@@ -0,0 +1,531 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// ConflictPolicy tells how Files.Sync resolves a file that changed both locally and in Box.com
+type ConflictPolicy string
+
+const (
+	// ConflictKeepBoth keeps both files, the local file is renamed and uploaded as a new file
+	ConflictKeepBoth ConflictPolicy = "keep_both"
+	// ConflictNewestWins keeps the file that was modified last
+	ConflictNewestWins ConflictPolicy = "newest_wins"
+	// ConflictRemoteWins keeps the file of Box.com
+	ConflictRemoteWins ConflictPolicy = "remote_wins"
+	// ConflictLocalWins keeps the local file
+	ConflictLocalWins ConflictPolicy = "local_wins"
+)
+
+// SyncOptions contains the options for synchronizing a local directory with a folder
+type SyncOptions struct {
+	// StatePath is the path of the file that keeps the state of the synchronization (default: SyncStateFilename in the directory)
+	StatePath string
+	// ConflictPolicy tells how conflicts are resolved (default: ConflictKeepBoth)
+	ConflictPolicy ConflictPolicy
+	// Workers is the number of files transferred at the same time (default: DefaultUploadWorkers)
+	Workers int
+	// Exclude are the glob patterns (see path.Match) of the files and folders not to synchronize
+	Exclude []string
+}
+
+// SyncStateFilename is the name of the file that keeps the state of a synchronization in the synchronized directory
+const SyncStateFilename = ".box-sync.json"
+
+// SyncReport tells what happened to the files that were synchronized
+type SyncReport struct {
+	Files []SyncResult `json:"files"`
+}
+
+// SyncResult tells what happened to a synchronized file
+type SyncResult struct {
+	// Path is the path of the file relative to the synchronized directory, with "/" as separator
+	Path string `json:"path"`
+	// Action is "uploaded", "downloaded", "deleted_local", "deleted_remote", "kept_both" or "failed"
+	Action string `json:"action"`
+	// Conflict tells if the file changed both locally and in Box.com
+	Conflict bool `json:"conflict,omitempty"`
+	// Error is the reason of the failure
+	Error error `json:"-"`
+}
+
+// syncState is the state of a synchronization as of its last run
+type syncState struct {
+	FolderID string                `json:"folder_id"`
+	Files    map[string]syncedFile `json:"files"`
+}
+
+// syncedFile is the state of a file that was synchronized
+type syncedFile struct {
+	ID         string    `json:"id"`
+	ETag       string    `json:"etag"`
+	Checksum   string    `json:"sha1"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// localFile is a file of the synchronized directory
+type localFile struct {
+	Size       int64
+	ModifiedAt time.Time
+	Checksum   string
+}
+
+// syncAction is an action to perform on a file to synchronize it
+type syncAction struct {
+	Path     string
+	Kind     string // "upload", "download", "delete_local", "delete_remote" or "conflict"
+	Local    *localFile
+	Remote   *FileEntry
+	Conflict bool
+}
+
+// syncer synchronizes a local directory with a folder
+type syncer struct {
+	module    *Files
+	directory string
+	folder    *FolderEntry
+	options   SyncOptions
+	statePath string
+	state     *syncState
+	folders   map[string]*PathEntry
+	mutex     sync.Mutex
+}
+
+// Sync synchronizes the files of a local directory and a Box.com folder in both directions
+//
+// The IDs, ETags and SHA1s of the synchronized files are kept in a state file, which tells what changed locally
+// and in Box.com since the last synchronization:
+//   - new and modified files are uploaded or downloaded,
+//   - files deleted on one side are deleted on the other side, unless they were modified there,
+//   - files modified on both sides are resolved according to options.ConflictPolicy.
+//
+// Only files are synchronized, folders are created as needed. The first synchronization of a folder
+// does not delete anything, files that exist on both sides with different content are conflicts.
+//
+// The state file must not be shared between folders, ArgumentInvalid is returned if it belongs to another folder.
+func (module *Files) Sync(ctx context.Context, folder *FolderEntry, directory string, options *SyncOptions) (*SyncReport, error) {
+	if folder == nil || len(folder.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("folder")
+	}
+	if len(directory) == 0 {
+		return nil, errors.ArgumentMissing.With("directory")
+	}
+	syncer := &syncer{module: module, directory: directory, folder: folder, folders: map[string]*PathEntry{}}
+	if options != nil {
+		syncer.options = *options
+	}
+	switch syncer.options.ConflictPolicy {
+	case "":
+		syncer.options.ConflictPolicy = ConflictKeepBoth
+	case ConflictKeepBoth, ConflictNewestWins, ConflictRemoteWins, ConflictLocalWins:
+	default:
+		return nil, errors.ArgumentInvalid.With("conflictPolicy", syncer.options.ConflictPolicy)
+	}
+	for _, pattern := range syncer.options.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.ArgumentInvalid.With("pattern", pattern)
+		}
+	}
+	if syncer.options.Workers <= 0 {
+		syncer.options.Workers = DefaultUploadWorkers
+	}
+	syncer.statePath = syncer.options.StatePath
+	if len(syncer.statePath) == 0 {
+		syncer.statePath = filepath.Join(directory, SyncStateFilename)
+	}
+	if info, err := os.Stat(directory); err != nil {
+		return nil, errors.WithStack(err)
+	} else if !info.IsDir() {
+		return nil, errors.ArgumentInvalid.With("directory", directory)
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	state, err := loadSyncState(syncer.statePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(state.FolderID) > 0 && state.FolderID != folder.ID {
+		return nil, errors.ArgumentInvalid.With("folder", folder.ID)
+	}
+	state.FolderID = folder.ID
+	syncer.state = state
+
+	locals, err := syncer.scanLocal()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := syncer.scanRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actions := syncer.plan(locals, remotes)
+	report := syncer.apply(ctx, actions)
+	if err := saveSyncState(syncer.statePath, syncer.state); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Failed gives the files that could not be synchronized
+func (report SyncReport) Failed() []SyncResult {
+	failed := []SyncResult{}
+	for _, result := range report.Files {
+		if result.Action == "failed" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// scanLocal lists the files of the local directory
+//
+// The SHA1 of a file is only computed if its size or modification time changed since the last synchronization.
+func (syncer *syncer) scanLocal() (map[string]*localFile, error) {
+	statePath, _ := filepath.Abs(syncer.statePath)
+	locals := map[string]*localFile{}
+	err := filepath.WalkDir(syncer.directory, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(syncer.directory, localPath)
+		relative = filepath.ToSlash(relative)
+		if entry.IsDir() {
+			if relative != "." && matchesAny(syncer.options.Exclude, relative) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if absolute, _ := filepath.Abs(localPath); absolute == statePath || absolute == statePath+".tmp" {
+			return nil
+		}
+		if !entry.Type().IsRegular() || strings.HasSuffix(relative, PartialDownloadSuffix) || matchesAny(syncer.options.Exclude, relative) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		local := &localFile{Size: info.Size(), ModifiedAt: info.ModTime()}
+		if synced, found := syncer.state.Files[relative]; found && synced.Size == local.Size && synced.ModifiedAt.Equal(local.ModifiedAt) {
+			local.Checksum = synced.Checksum
+		} else if local.Checksum, err = localChecksum(localPath); err != nil {
+			return err
+		}
+		locals[relative] = local
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return locals, nil
+}
+
+// scanRemote lists the files of the folder and keeps its subfolders
+func (syncer *syncer) scanRemote(ctx context.Context) (map[string]*FileEntry, error) {
+	remotes := map[string]*FileEntry{}
+	err := syncer.module.Client.Folders.Walk(ctx, syncer.folder, func(itemPath string, item *Item, err error) error {
+		if err != nil {
+			return err
+		}
+		relative := strings.TrimPrefix(itemPath, "/")
+		if len(relative) == 0 {
+			relative = "."
+		}
+		switch {
+		case item.Folder != nil:
+			if relative != "." && matchesAny(syncer.options.Exclude, relative) {
+				return fs.SkipDir
+			}
+			syncer.folders[relative] = item.Folder.AsPathEntry()
+		case item.File != nil:
+			if !matchesAny(syncer.options.Exclude, relative) {
+				remotes[relative] = item.File
+			}
+		}
+		return nil
+	}, &WalkOptions{Fields: []string{"type", "id", "name", "size", "sha1", "etag", "content_modified_at"}})
+	return remotes, err
+}
+
+// plan compares the local files, the remote files and the state of the last synchronization and gives the actions to perform
+//
+// Files that are already synchronized are recorded in the state, files that are gone on both sides are forgotten.
+func (syncer *syncer) plan(locals map[string]*localFile, remotes map[string]*FileEntry) []syncAction {
+	paths := map[string]bool{}
+	for relative := range locals {
+		paths[relative] = true
+	}
+	for relative := range remotes {
+		paths[relative] = true
+	}
+	for relative := range syncer.state.Files {
+		paths[relative] = true
+	}
+
+	actions := []syncAction{}
+	for relative := range paths {
+		local, remote := locals[relative], remotes[relative]
+		synced, known := syncer.state.Files[relative]
+		localChanged := local != nil && (!known || !strings.EqualFold(local.Checksum, synced.Checksum))
+		remoteChanged := remote != nil && (!known || remote.ID != synced.ID || !strings.EqualFold(remote.Checksum, synced.Checksum))
+		action := syncAction{Path: relative, Local: local, Remote: remote}
+
+		switch {
+		case local == nil && remote == nil:
+			delete(syncer.state.Files, relative)
+			continue
+		case local != nil && remote != nil:
+			if strings.EqualFold(local.Checksum, remote.Checksum) {
+				syncer.state.Files[relative] = syncedFile{ID: remote.ID, ETag: remote.ETag, Checksum: remote.Checksum, Size: local.Size, ModifiedAt: local.ModifiedAt}
+				continue
+			}
+			switch {
+			case localChanged && remoteChanged:
+				action.Kind, action.Conflict = "conflict", true
+			case localChanged:
+				action.Kind = "upload"
+			default:
+				action.Kind = "download"
+			}
+		case local != nil:
+			// The file was deleted in Box.com, unless it was modified locally since
+			if known && !localChanged {
+				action.Kind = "delete_local"
+			} else {
+				action.Kind = "upload"
+			}
+		default:
+			// The file was deleted locally, unless it was modified in Box.com since
+			if known && !remoteChanged {
+				action.Kind = "delete_remote"
+			} else {
+				action.Kind = "download"
+			}
+		}
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i].Path < actions[j].Path })
+	return actions
+}
+
+// apply performs the given actions with the configured number of workers
+func (syncer *syncer) apply(ctx context.Context, actions []syncAction) *SyncReport {
+	report := &SyncReport{Files: make([]SyncResult, len(actions))}
+	for index, action := range actions {
+		report.Files[index] = SyncResult{Path: action.Path, Conflict: action.Conflict}
+		if action.Kind == "upload" || action.Kind == "conflict" {
+			// Folders are created before the workers start, so they are not created twice
+			if _, err := syncer.remoteFolder(ctx, path.Dir(action.Path)); err != nil {
+				report.Files[index].Action, report.Files[index].Error = "failed", err
+			}
+		}
+	}
+
+	jobs := make(chan int)
+	var wait sync.WaitGroup
+	for worker := 0; worker < syncer.options.Workers; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range jobs {
+				result := &report.Files[index]
+				if result.Action, result.Error = syncer.perform(ctx, actions[index]); result.Error != nil {
+					result.Action = "failed"
+				}
+			}
+		}()
+	}
+	for index := range actions {
+		if report.Files[index].Action != "failed" {
+			jobs <- index
+		}
+	}
+	close(jobs)
+	wait.Wait()
+	return report
+}
+
+// perform performs an action and records the result in the state
+func (syncer *syncer) perform(ctx context.Context, action syncAction) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "failed", errors.WithStack(err)
+	}
+	switch action.Kind {
+	case "upload":
+		return "uploaded", syncer.upload(ctx, action.Path, action.Remote)
+	case "download":
+		return "downloaded", syncer.download(ctx, action.Path, action.Remote)
+	case "delete_local":
+		if err := os.Remove(syncer.localPath(action.Path)); err != nil && !os.IsNotExist(err) {
+			return "failed", errors.WithStack(err)
+		}
+		syncer.forget(action.Path)
+		return "deleted_local", nil
+	case "delete_remote":
+		if err := syncer.module.Delete(ctx, action.Remote); err != nil && !errors.Is(err, errors.NotFound) {
+			return "failed", err
+		}
+		syncer.forget(action.Path)
+		return "deleted_remote", nil
+	}
+
+	switch syncer.options.ConflictPolicy {
+	case ConflictRemoteWins:
+		return "downloaded", syncer.download(ctx, action.Path, action.Remote)
+	case ConflictLocalWins:
+		return "uploaded", syncer.upload(ctx, action.Path, action.Remote)
+	case ConflictNewestWins:
+		if action.Local.ModifiedAt.After(action.Remote.ContentModifiedAt) {
+			return "uploaded", syncer.upload(ctx, action.Path, action.Remote)
+		}
+		return "downloaded", syncer.download(ctx, action.Path, action.Remote)
+	}
+	// Keeping both: the local file is renamed and uploaded as a new file, the remote file is downloaded
+	conflictPath := conflictName(action.Path, time.Now())
+	if err := os.Rename(syncer.localPath(action.Path), syncer.localPath(conflictPath)); err != nil {
+		return "failed", errors.WithStack(err)
+	}
+	if err := syncer.upload(ctx, conflictPath, nil); err != nil {
+		return "failed", err
+	}
+	if err := syncer.download(ctx, action.Path, action.Remote); err != nil {
+		return "failed", err
+	}
+	return "kept_both", nil
+}
+
+// upload uploads a local file, as a new version of remote if it is not nil
+func (syncer *syncer) upload(ctx context.Context, relative string, remote *FileEntry) error {
+	folder, err := syncer.remoteFolder(ctx, path.Dir(relative))
+	if err != nil {
+		return err
+	}
+	var existing *Item
+	if remote != nil {
+		existing = &Item{Type: "file", File: remote}
+	}
+	status, entry, err := syncer.module.uploadLocalFile(ctx, syncer.localPath(relative), folder, existing)
+	if err != nil {
+		return err
+	}
+	if status == "failed" || entry == nil {
+		return errors.NotFound.With("file", relative)
+	}
+	return syncer.record(relative, entry)
+}
+
+// download downloads a remote file
+func (syncer *syncer) download(ctx context.Context, relative string, remote *FileEntry) error {
+	localPath := syncer.localPath(relative)
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := syncer.module.downloadLocalFile(ctx, remote, localPath); err != nil {
+		return err
+	}
+	return syncer.record(relative, remote)
+}
+
+// record records a synchronized file in the state
+func (syncer *syncer) record(relative string, entry *FileEntry) error {
+	info, err := os.Stat(syncer.localPath(relative))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	syncer.state.Files[relative] = syncedFile{ID: entry.ID, ETag: entry.ETag, Checksum: entry.Checksum, Size: info.Size(), ModifiedAt: info.ModTime()}
+	return nil
+}
+
+// forget removes a file from the state
+func (syncer *syncer) forget(relative string) {
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	delete(syncer.state.Files, relative)
+}
+
+// remoteFolder gives the folder at the given relative path, creating it if needed
+func (syncer *syncer) remoteFolder(ctx context.Context, relative string) (*PathEntry, error) {
+	syncer.mutex.Lock()
+	folder, found := syncer.folders[relative]
+	syncer.mutex.Unlock()
+	if found {
+		return folder, nil
+	}
+	if relative == "." {
+		return syncer.folder.AsPathEntry(), nil
+	}
+	parent, err := syncer.remoteFolder(ctx, path.Dir(relative))
+	if err != nil {
+		return nil, err
+	}
+	created, err := syncer.module.Client.Folders.createOrGet(ctx, path.Base(relative), parent, false)
+	if err != nil {
+		return nil, err
+	}
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	syncer.folders[relative] = created.AsPathEntry()
+	return syncer.folders[relative], nil
+}
+
+// localPath gives the local path of a relative path
+func (syncer *syncer) localPath(relative string) string {
+	return filepath.Join(syncer.directory, filepath.FromSlash(relative))
+}
+
+// conflictName gives the name of the conflicting copy of a file, e.g.: "report (conflict 2024-01-02 150405).pdf"
+func conflictName(relative string, now time.Time) string {
+	extension := path.Ext(relative)
+	return strings.TrimSuffix(relative, extension) + " (conflict " + now.Format("2006-01-02 150405") + ")" + extension
+}
+
+// loadSyncState loads the state of a synchronization, an empty state is given if the file does not exist
+func loadSyncState(statePath string) (*syncState, error) {
+	state := &syncState{Files: map[string]syncedFile{}}
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if state.Files == nil {
+		state.Files = map[string]syncedFile{}
+	}
+	return state, nil
+}
+
+// saveSyncState saves the state of a synchronization, the file is replaced only once the new state is written entirely
+func saveSyncState(statePath string, state *syncState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.JSONMarshalError.Wrap(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(statePath+".tmp", data, 0600); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(statePath+".tmp", statePath))
+}
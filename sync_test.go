@@ -0,0 +1,291 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type SyncSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server     *httptest.Server
+	ServerURL  *url.URL
+	Mutex      sync.Mutex
+	Remote     map[string]*syncNode
+	Deleted    []string
+	ModifiedAt time.Time
+}
+
+func TestSyncSuite(t *testing.T) {
+	suite.Run(t, new(SyncSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *SyncSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.ModifiedAt = time.Date(2021, time.February, 3, 4, 5, 6, 0, time.UTC)
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *SyncSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *SyncSuite) BeforeTest(suiteName, testName string) {
+	suite.Mutex.Lock()
+	suite.Remote = map[string]*syncNode{
+		"1":  {Type: "folder", Name: "docs", Parent: "0"},
+		"10": {Type: "file", Name: "hello.txt", Parent: "0", Data: []byte("Hello, World!")},
+		"11": {Type: "file", Name: "report.pdf", Parent: "1", Data: bytes.Repeat([]byte("%PDF"), 100)},
+	}
+	suite.Deleted = []string{}
+	suite.Mutex.Unlock()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *SyncSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+type syncNode struct {
+	Type   string
+	Name   string
+	Parent string
+	Data   []byte
+}
+
+func (node syncNode) Checksum() string {
+	checksum := sha1.Sum(node.Data)
+	return hex.EncodeToString(checksum[:])
+}
+
+func (suite *SyncSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		suite.Mutex.Lock()
+		defer suite.Mutex.Unlock()
+		segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/2.0/"), "/")
+		switch {
+		case len(segments) == 3 && segments[0] == "folders" && segments[2] == "items":
+			entries := []map[string]interface{}{}
+			for id, node := range suite.Remote {
+				if node.Parent != segments[1] {
+					continue
+				}
+				entry := map[string]interface{}{"type": node.Type, "id": id, "name": node.Name, "etag": "0"}
+				if node.Type == "file" {
+					entry["size"] = len(node.Data)
+					entry["sha1"] = node.Checksum()
+					entry["content_modified_at"] = suite.ModifiedAt.Format(time.RFC3339)
+				}
+				entries = append(entries, entry)
+			}
+			res.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(res).Encode(map[string]interface{}{"entries": entries, "limit": 1000, "next_marker": ""})
+		case len(segments) == 3 && segments[0] == "files" && segments[2] == "content":
+			node, found := suite.Remote[segments[1]]
+			if !found {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			http.ServeContent(res, req, node.Name, time.Now(), bytes.NewReader(node.Data))
+		case req.Method == http.MethodDelete && len(segments) == 2 && segments[0] == "files":
+			if _, found := suite.Remote[segments[1]]; !found {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			suite.Assert().Equal("0", req.Header.Get("If-Match"))
+			delete(suite.Remote, segments[1])
+			suite.Deleted = append(suite.Deleted, segments[1])
+			res.WriteHeader(http.StatusNoContent)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *SyncSuite) Actions(report *SyncReport) map[string]string {
+	actions := map[string]string{}
+	for _, result := range report.Files {
+		actions[result.Path] = result.Action
+	}
+	return actions
+}
+
+func (suite *SyncSuite) TestCanSyncRemoteChanges() {
+	directory := suite.T().TempDir()
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	report, err := client.Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"hello.txt": "downloaded", "docs/report.pdf": "downloaded"}, suite.Actions(report))
+	suite.Assert().FileExists(filepath.Join(directory, SyncStateFilename))
+	data, err := os.ReadFile(filepath.Join(directory, "docs", "report.pdf"))
+	suite.Require().Nilf(err, "Failed reading synced file. Error: %s", err)
+	suite.Assert().Equal(suite.Remote["11"].Data, data)
+
+	report, err = client.Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing again. Error: %s", err)
+	suite.Assert().Empty(report.Files, "Nothing should have changed")
+
+	suite.Mutex.Lock()
+	suite.Remote["10"].Data = []byte("Hello again!")
+	delete(suite.Remote, "11")
+	suite.Mutex.Unlock()
+	report, err = client.Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing remote changes. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"hello.txt": "downloaded", "docs/report.pdf": "deleted_local"}, suite.Actions(report))
+	suite.Assert().NoFileExists(filepath.Join(directory, "docs", "report.pdf"))
+	data, err = os.ReadFile(filepath.Join(directory, "hello.txt"))
+	suite.Require().Nilf(err, "Failed reading synced file. Error: %s", err)
+	suite.Assert().Equal("Hello again!", string(data))
+}
+
+func (suite *SyncSuite) TestCanSyncLocalDeletions() {
+	directory := suite.T().TempDir()
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing. Error: %s", err)
+
+	err = os.Remove(filepath.Join(directory, "hello.txt"))
+	suite.Require().Nilf(err, "Failed deleting local file. Error: %s", err)
+	report, err := client.Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().Nilf(err, "Failed syncing local deletion. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"hello.txt": "deleted_remote"}, suite.Actions(report))
+	suite.Assert().Equal([]string{"10"}, suite.Deleted)
+}
+
+func (suite *SyncSuite) TestCanResolveConflictWithRemoteWins() {
+	directory := suite.T().TempDir()
+	err := os.WriteFile(filepath.Join(directory, "hello.txt"), []byte("Bye, World!"), 0644)
+	suite.Require().Nilf(err, "Failed writing local file. Error: %s", err)
+
+	report, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, &SyncOptions{
+		ConflictPolicy: ConflictRemoteWins,
+		Exclude:        []string{"docs"},
+	})
+	suite.Require().Nilf(err, "Failed syncing. Error: %s", err)
+	suite.Require().Len(report.Files, 1)
+	suite.Assert().Equal("downloaded", report.Files[0].Action)
+	suite.Assert().True(report.Files[0].Conflict, "The file should have been reported as a conflict")
+	data, err := os.ReadFile(filepath.Join(directory, "hello.txt"))
+	suite.Require().Nilf(err, "Failed reading synced file. Error: %s", err)
+	suite.Assert().Equal("Hello, World!", string(data))
+}
+
+func (suite *SyncSuite) TestCanPlanSync() {
+	syncer := &syncer{state: &syncState{Files: map[string]syncedFile{
+		"unchanged.txt":      {ID: "1", Checksum: "aaa"},
+		"local-changed.txt":  {ID: "2", Checksum: "aaa"},
+		"remote-changed.txt": {ID: "3", Checksum: "aaa"},
+		"both-changed.txt":   {ID: "4", Checksum: "aaa"},
+		"local-deleted.txt":  {ID: "5", Checksum: "aaa"},
+		"remote-deleted.txt": {ID: "6", Checksum: "aaa"},
+		"edited-deleted.txt": {ID: "7", Checksum: "aaa"},
+		"gone.txt":           {ID: "8", Checksum: "aaa"},
+	}}}
+	locals := map[string]*localFile{
+		"unchanged.txt":      {Checksum: "aaa"},
+		"local-changed.txt":  {Checksum: "bbb"},
+		"remote-changed.txt": {Checksum: "aaa"},
+		"both-changed.txt":   {Checksum: "bbb"},
+		"remote-deleted.txt": {Checksum: "aaa"},
+		"edited-deleted.txt": {Checksum: "bbb"},
+		"new-local.txt":      {Checksum: "ccc"},
+	}
+	remotes := map[string]*FileEntry{
+		"unchanged.txt":      {ID: "1", Checksum: "aaa"},
+		"local-changed.txt":  {ID: "2", Checksum: "aaa"},
+		"remote-changed.txt": {ID: "3", Checksum: "bbb"},
+		"both-changed.txt":   {ID: "4", Checksum: "ccc"},
+		"local-deleted.txt":  {ID: "5", Checksum: "aaa"},
+		"new-remote.txt":     {ID: "9", Checksum: "ddd"},
+	}
+	kinds := map[string]string{}
+	for _, action := range syncer.plan(locals, remotes) {
+		kinds[action.Path] = action.Kind
+	}
+	suite.Assert().Equal(map[string]string{
+		"local-changed.txt":  "upload",
+		"remote-changed.txt": "download",
+		"both-changed.txt":   "conflict",
+		"local-deleted.txt":  "delete_remote",
+		"remote-deleted.txt": "delete_local",
+		"edited-deleted.txt": "upload",
+		"new-local.txt":      "upload",
+		"new-remote.txt":     "download",
+	}, kinds)
+	suite.Assert().Contains(syncer.state.Files, "unchanged.txt")
+	suite.Assert().NotContains(syncer.state.Files, "gone.txt")
+}
+
+func (suite *SyncSuite) TestCanNameConflicts() {
+	now := time.Date(2024, time.January, 2, 15, 4, 5, 0, time.UTC)
+	suite.Assert().Equal("docs/report (conflict 2024-01-02 150405).pdf", conflictName("docs/report.pdf", now))
+	suite.Assert().Equal("README (conflict 2024-01-02 150405)", conflictName("README", now))
+}
+
+func (suite *SyncSuite) TestShouldFailSyncingWithStateOfAnotherFolder() {
+	directory := suite.T().TempDir()
+	err := os.WriteFile(filepath.Join(directory, SyncStateFilename), []byte(`{"folder_id":"1234","files":{}}`), 0600)
+	suite.Require().Nilf(err, "Failed writing state file. Error: %s", err)
+
+	_, err = newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.Sync(context.Background(), &FolderEntry{ID: "0"}, directory, nil)
+	suite.Require().NotNil(err, "Should have failed syncing")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an Argument Invalid Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("folder", details.What)
+}
+
+func (suite *SyncSuite) TestShouldFailSyncingWithInvalidConflictPolicy() {
+	_, err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Files.Sync(context.Background(), &FolderEntry{ID: "0"}, suite.T().TempDir(), &SyncOptions{ConflictPolicy: "oldest_wins"})
+	suite.Require().NotNil(err, "Should have failed syncing")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an Argument Invalid Error. Error: %v", err)
+}
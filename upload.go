@@ -1,23 +1,68 @@
 package box
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/gildas/go-core"
 	"github.com/gildas/go-errors"
 	"github.com/gildas/go-request"
 )
 
 // UploadOptions contains the options for uploading data
 type UploadOptions struct {
-	Parent   *PathEntry
-	Filename string
-	Content  *request.Content
-	Payload  interface{}
+	Parent            *PathEntry
+	Filename          string
+	Description       string
+	ContentCreatedAt  time.Time
+	ContentModifiedAt time.Time
+	Content           *request.Content
+	Payload           interface{}
+	// File, if not nil, is the existing file to upload a new version of
+	File *FileEntry
+	// Reader, if not nil, is streamed instead of Content, its content type is guessed from the extension of Filename
+	//
+	// If it is also an io.Seeker, its SHA1 is computed before the upload so Box.com can verify it.
+	Reader io.Reader
+	// ProgressWriter, if not nil, receives the uploaded data as it is sent
+	ProgressWriter io.Writer
+	// OnProgress, if not nil, is called with the progress of the upload
+	OnProgress ProgressFunc
+}
+
+// uploadAttributes are the attributes sent with the content of an upload
+type uploadAttributes struct {
+	Name              string     `json:"name"`
+	Parent            PathEntry  `json:"parent"`
+	ContentCreatedAt  *core.Time `json:"content_created_at,omitempty"`
+	ContentModifiedAt *core.Time `json:"content_modified_at,omitempty"`
 }
 
 // Upload uploads data to Box.com
+//
+// The content is streamed to Box.com. When its SHA1 can be computed beforehand (i.e. options.Content is used or options.Reader is an io.Seeker),
+// it is sent in the Content-MD5 header and Box.com will refuse the upload with BadDigest if it does not match what it received.
+//
+// If options.File is set, the content is uploaded as a new version of that file, the previous content becoming a FileVersion.
+// options.Filename then defaults to the name of the file (it is renamed otherwise) and options.Parent and options.ContentCreatedAt are ignored.
+// If options.File.ETag is set, the content is only uploaded if the file has not changed since, otherwise PreconditionFailed is returned.
+//
+// If options.ContentCreatedAt or options.ContentModifiedAt are set, they are used
+// instead of the upload time. As Box.com does not accept a description during the upload,
+// the description is set once the file is uploaded. If that fails, the uploaded files are returned with the error.
 func (module *Files) Upload(ctx context.Context, options *UploadOptions) (*FileCollection, error) {
 	//log := module.Client.Logger.Scope("upload")
 
@@ -26,7 +71,10 @@ func (module *Files) Upload(ctx context.Context, options *UploadOptions) (*FileC
 		return nil, errors.ArgumentMissing.With("options")
 	}
 
-	if len(options.Filename) == 0 {
+	if options.File != nil && len(options.File.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("file")
+	}
+	if len(options.Filename) == 0 && options.File == nil {
 		return nil, errors.ArgumentMissing.With("filename")
 	}
 	if options.Payload != nil {
@@ -37,30 +85,233 @@ func (module *Files) Upload(ctx context.Context, options *UploadOptions) (*FileC
 		options.Content = request.ContentWithData(payload, "application/json")
 	}
 
-	if options.Content == nil {
+	if options.Content == nil && options.Reader == nil {
 		return nil, errors.ArgumentMissing.With("content")
 	}
 	if !module.Client.IsAuthenticated() {
 		return nil, errors.Unauthorized.WithStack()
 	}
 
-	parentID := "0"
-	if options.Parent != nil && len(options.Parent.ID) > 0 {
-		parentID = options.Parent.ID
+	var uploadURL *url.URL
+	var headers map[string]string
+	var attributes interface{}
+	filename := options.Filename
+	if options.File != nil {
+		if len(filename) == 0 {
+			filename = options.File.Name
+		}
+		uploadURL, _ = module.Client.UploadApi.Parse("files/" + options.File.ID + "/content")
+		headers = ifMatch(options.File.ETag)
+		attributes = struct {
+			Name              string     `json:"name,omitempty"`
+			ContentModifiedAt *core.Time `json:"content_modified_at,omitempty"`
+		}{options.Filename, optionalTime(options.ContentModifiedAt)}
+	} else {
+		parentID := "0"
+		if options.Parent != nil && len(options.Parent.ID) > 0 {
+			parentID = options.Parent.ID
+		}
+		uploadURL, _ = module.Client.UploadApi.Parse("files/content")
+		attributes = uploadAttributes{
+			Name:              options.Filename,
+			Parent:            PathEntry{ID: parentID},
+			ContentCreatedAt:  optionalTime(options.ContentCreatedAt),
+			ContentModifiedAt: optionalTime(options.ContentModifiedAt),
+		}
 	}
 
-	uploadURL, _ := url.Parse("https://upload.box.com/api/2.0/files/content")
 	results := FileCollection{}
-	if _, err := module.Client.sendRequest(ctx, &request.Options{
-		URL: uploadURL,
-		Payload: map[string]string{
-			"name":      options.Filename,
-			"parent_id": parentID,
-			">file":     options.Filename,
-		},
-		Attachment: options.Content.Reader(),
-	}, &results); err != nil {
+	if err := module.sendUpload(ctx, uploadURL, headers, attributes, filename, options, &results); err != nil {
 		return nil, err
 	}
+
+	if len(options.Description) > 0 {
+		for index, entry := range results.Files {
+			updated, err := module.Update(ctx, &entry, &FileUpdateOptions{Description: &options.Description})
+			if err != nil {
+				return &results, err
+			}
+			results.Files[index] = *updated
+		}
+	}
 	return &results, nil
 }
+
+// UploadFile uploads a local file to Box.com
+//
+// If options is nil, the file is uploaded in the root folder.
+//
+// options.Filename defaults to the base name of the file and
+// options.ContentCreatedAt and options.ContentModifiedAt default to the modification time of the file.
+//
+// The file is streamed, it is not loaded in memory.
+func (module *Files) UploadFile(ctx context.Context, path string, options *UploadOptions) (*FileCollection, error) {
+	if len(path) == 0 {
+		return nil, errors.ArgumentMissing.With("path")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+	fileOptions, err := localUploadOptions(file, options)
+	if err != nil {
+		return nil, err
+	}
+	return module.Upload(ctx, fileOptions)
+}
+
+// localUploadOptions gives the UploadOptions to upload the content of an opened local file
+func localUploadOptions(file *os.File, options *UploadOptions) (*UploadOptions, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if info.IsDir() {
+		return nil, errors.ArgumentInvalid.With("path", file.Name())
+	}
+
+	fileOptions := UploadOptions{}
+	if options != nil {
+		fileOptions = *options
+	}
+	if len(fileOptions.Filename) == 0 {
+		fileOptions.Filename = filepath.Base(file.Name())
+	}
+	if fileOptions.ContentCreatedAt.IsZero() {
+		fileOptions.ContentCreatedAt = info.ModTime()
+	}
+	if fileOptions.ContentModifiedAt.IsZero() {
+		fileOptions.ContentModifiedAt = info.ModTime()
+	}
+	fileOptions.Reader = file
+	fileOptions.Content = nil
+	fileOptions.Payload = nil
+	return &fileOptions, nil
+}
+
+// sendUpload streams the multipart body of an upload to the given URL and decodes the results
+//
+// The attributes part is written before the file part as required by Box.com.
+// If the content is an io.Seeker, the upload is sent again when Box.com asks to retry later.
+func (module *Files) sendUpload(ctx context.Context, uploadURL *url.URL, headers map[string]string, attributes interface{}, filename string, options *UploadOptions, results interface{}) (err error) {
+	payload, err := json.Marshal(attributes)
+	if err != nil {
+		return errors.JSONMarshalError.Wrap(err)
+	}
+	content, contentType, size, checksum, err := uploadSource(options, filename)
+	if err != nil {
+		return err
+	}
+
+	// The multipart header and trailer are built beforehand, so the content is streamed between them
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+	if err := writer.WriteField("attributes", string(payload)); err != nil {
+		return errors.WithStack(err)
+	}
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	partHeader.Set("Content-Type", contentType)
+	if _, err := writer.CreatePart(partHeader); err != nil {
+		return errors.WithStack(err)
+	}
+	head := append([]byte{}, buffer.Bytes()...)
+	buffer.Reset()
+	if err := writer.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	tail := buffer.Bytes()
+
+	length := int64(-1)
+	if size >= 0 {
+		length = int64(len(head)) + size + int64(len(tail))
+	}
+	// Only the content is tracked, so the progress does not include the multipart framing
+	source := content
+	tracker := newProgressTracker(options.ProgressWriter, options.OnProgress, size)
+	if tracker != nil {
+		defer func() { tracker.finish(err) }()
+		source = io.TeeReader(content, tracker)
+	}
+	newBody := func() io.Reader {
+		return io.MultiReader(bytes.NewReader(head), source, bytes.NewReader(tail))
+	}
+
+	// The upload can be sent again only if the content can be read again
+	var rewind func() (io.Reader, error)
+	if seeker, ok := content.(io.Seeker); ok && size >= 0 {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rewind = func() (io.Reader, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if tracker != nil {
+				tracker.restart()
+			}
+			return newBody(), nil
+		}
+	}
+
+	uploadHeaders := map[string]string{"Content-Type": writer.FormDataContentType()}
+	for key, value := range headers {
+		uploadHeaders[key] = value
+	}
+	if len(checksum) > 0 {
+		uploadHeaders["Content-MD5"] = checksum
+	}
+	return module.Client.sendUploadRequest(ctx, uploadURL, uploadHeaders, newBody(), length, rewind, results)
+}
+
+// uploadSource gives the reader, the content type, the size and the SHA1 of the content to upload
+//
+// If the content cannot be read twice, its size is -1 and its SHA1 is empty.
+func uploadSource(options *UploadOptions, filename string) (io.Reader, string, int64, string, error) {
+	reader := options.Reader
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if reader == nil {
+		reader = options.Content.Reader()
+		contentType = options.Content.Type
+	}
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return reader, contentType, -1, "", nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, "", 0, "", errors.WithStack(err)
+	}
+	hasher := sha1.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return nil, "", 0, "", errors.WithStack(err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, "", 0, "", errors.WithStack(err)
+	}
+	return reader, contentType, size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// optionalTime gives a *core.Time from the given time or nil if it is zero
+func optionalTime(value time.Time) *core.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return (*core.Time)(&value)
+}
+
+// timeOrZero gives a time.Time from the given *core.Time or the zero time if it is nil
+func timeOrZero(value *core.Time) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	return (time.Time)(*value)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -0,0 +1,211 @@
+package box
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gildas/go-errors"
+)
+
+// UploadDirectoryOptions contains the options for uploading a local directory
+type UploadDirectoryOptions struct {
+	// Workers is the number of files uploaded at the same time (default: DefaultUploadWorkers)
+	Workers int
+}
+
+// DefaultUploadWorkers is the default number of files uploaded at the same time by Files.UploadDirectory
+const DefaultUploadWorkers = 4
+
+// UploadReport tells what happened to each file of an uploaded directory
+type UploadReport struct {
+	Files []UploadResult `json:"files"`
+}
+
+// UploadResult tells what happened to a file of an uploaded directory
+type UploadResult struct {
+	// Path is the path of the file relative to the uploaded directory, with "/" as separator
+	Path string `json:"path"`
+	// Status is "created", "updated", "skipped" or "failed"
+	Status string `json:"status"`
+	// File is the FileEntry on Box.com, nil if the upload failed
+	File *FileEntry `json:"file,omitempty"`
+	// Error is the reason of the failure
+	Error error `json:"-"`
+}
+
+// UploadDirectory mirrors a local directory into the given Box.com folder
+//
+// The subfolders are created as needed and the files are uploaded by options.Workers workers.
+// Files whose SHA1 matches the file with the same name in Box.com are skipped, files that differ are uploaded as a new version.
+// Only regular files are uploaded, symbolic links are ignored.
+//
+// The report tells what happened to each file, in the order of filepath.WalkDir.
+// Files that could not be uploaded are reported as "failed" and do not stop the upload,
+// an error is returned only when the directory could not be mirrored (e.g.: a folder could not be created).
+func (module *Files) UploadDirectory(ctx context.Context, directory string, parent *PathEntry, options *UploadDirectoryOptions) (*UploadReport, error) {
+	if len(directory) == 0 {
+		return nil, errors.ArgumentMissing.With("directory")
+	}
+	if parent == nil || len(parent.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("parent")
+	}
+	if info, err := os.Stat(directory); err != nil {
+		return nil, errors.WithStack(err)
+	} else if !info.IsDir() {
+		return nil, errors.ArgumentInvalid.With("directory", directory)
+	}
+	if options == nil {
+		options = &UploadDirectoryOptions{}
+	}
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultUploadWorkers
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	folders := []string{}
+	report := &UploadReport{Files: []UploadResult{}}
+	err := filepath.WalkDir(directory, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(directory, localPath)
+		relative = filepath.ToSlash(relative)
+		switch {
+		case entry.IsDir():
+			folders = append(folders, relative)
+		case entry.Type().IsRegular():
+			report.Files = append(report.Files, UploadResult{Path: relative})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Creating the folders and listing what they already contain
+	remoteFolders := map[string]*PathEntry{}
+	remoteItems := map[string]Item{}
+	for _, folder := range folders {
+		remote := parent
+		if folder != "." {
+			created, err := module.Client.Folders.createOrGet(ctx, path.Base(folder), remoteFolders[path.Dir(folder)], false)
+			if err != nil {
+				return report, err
+			}
+			remote = created.AsPathEntry()
+		}
+		remoteFolders[folder] = remote
+		items := module.Client.Folders.Items(ctx, remote, &ItemsOptions{Fields: []string{"type", "id", "name", "etag", "sha1"}})
+		for items.Next() {
+			item := items.Item()
+			remoteItems[path.Join(folder, module.Client.nameKey(item.Name()))] = *item
+		}
+		if err := items.Err(); err != nil {
+			return report, err
+		}
+	}
+
+	jobs := make(chan int)
+	var wait sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for index := range jobs {
+				result := &report.Files[index]
+				folder := path.Dir(result.Path)
+				remote, found := remoteItems[path.Join(folder, module.Client.nameKey(path.Base(result.Path)))]
+				var existing *Item
+				if found {
+					existing = &remote
+				}
+				result.Status, result.File, result.Error = module.uploadLocalFile(ctx, filepath.Join(directory, filepath.FromSlash(result.Path)), remoteFolders[folder], existing)
+			}
+		}()
+	}
+	for index := range report.Files {
+		jobs <- index
+	}
+	close(jobs)
+	wait.Wait()
+	return report, nil
+}
+
+// Failed gives the files that could not be uploaded
+func (report UploadReport) Failed() []UploadResult {
+	failed := []UploadResult{}
+	for _, result := range report.Files {
+		if result.Status == "failed" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// uploadLocalFile uploads a local file in the given folder, existing is the item with the same name in that folder, if any
+func (module *Files) uploadLocalFile(ctx context.Context, localPath string, folder *PathEntry, existing *Item) (string, *FileEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return "failed", nil, errors.WithStack(err)
+	}
+	if existing != nil {
+		if existing.File == nil {
+			return "failed", nil, errors.WithStack(ItemNameInUse)
+		}
+		checksum, err := localChecksum(localPath)
+		if err != nil {
+			return "failed", nil, err
+		}
+		if strings.EqualFold(checksum, existing.File.Checksum) {
+			return "skipped", existing.File, nil
+		}
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "failed", nil, errors.WithStack(err)
+	}
+	defer file.Close()
+	options, err := localUploadOptions(file, &UploadOptions{Parent: folder})
+	if err != nil {
+		return "failed", nil, err
+	}
+	status := "created"
+	if existing != nil {
+		status = "updated"
+		options.File = existing.File
+		options.Filename = "" // keep the remote name, which may differ by case
+	}
+	collection, err := module.Upload(ctx, options)
+	if err != nil {
+		return "failed", nil, err
+	}
+	if len(collection.Files) == 0 {
+		return "failed", nil, errors.NotFound.With("file", localPath)
+	}
+	return status, &collection.Files[0], nil
+}
+
+// localChecksum computes the SHA1 of a local file
+func localChecksum(localPath string) (string, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer file.Close()
+	hasher := sha1.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,286 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type UploadDirectorySuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Mutex     sync.Mutex
+	Remote    map[string]*syncNode
+	// Refused are the names of the files the fake server refuses to upload
+	Refused []string
+}
+
+func TestUploadDirectorySuite(t *testing.T) {
+	suite.Run(t, new(UploadDirectorySuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *UploadDirectorySuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *UploadDirectorySuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *UploadDirectorySuite) BeforeTest(suiteName, testName string) {
+	suite.Mutex.Lock()
+	suite.Remote = map[string]*syncNode{
+		"1":  {Type: "folder", Name: "docs", Parent: "0"},
+		"10": {Type: "file", Name: "hello.txt", Parent: "0", Data: []byte("Hello, World!")},
+	}
+	suite.Refused = []string{}
+	suite.Mutex.Unlock()
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *UploadDirectorySuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *UploadDirectorySuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		suite.Mutex.Lock()
+		defer suite.Mutex.Unlock()
+		res.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/2.0/folders/") && strings.HasSuffix(req.URL.Path, "/items"):
+			folderID := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/2.0/folders/"), "/items")
+			entries := []map[string]interface{}{}
+			for id, node := range suite.Remote {
+				if node.Parent != folderID {
+					continue
+				}
+				entry := map[string]interface{}{"type": node.Type, "id": id, "name": node.Name, "etag": "0"}
+				if node.Type == "file" {
+					entry["sha1"] = node.Checksum()
+				}
+				entries = append(entries, entry)
+			}
+			_ = json.NewEncoder(res).Encode(map[string]interface{}{"entries": entries, "limit": 1000, "next_marker": ""})
+		case req.Method == http.MethodPost && strings.TrimSuffix(req.URL.Path, "/") == "/2.0/folders":
+			var body struct {
+				Name   string `json:"name"`
+				Parent struct {
+					ID string `json:"id"`
+				} `json:"parent"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&body)
+			for id, node := range suite.Remote {
+				if node.Parent == body.Parent.ID && strings.EqualFold(node.Name, body.Name) {
+					res.WriteHeader(http.StatusConflict)
+					_ = json.NewEncoder(res).Encode(map[string]interface{}{
+						"type": "error", "status": 409, "code": "item_name_in_use", "message": "Item with the same name already exists",
+						"context_info": map[string]interface{}{"conflicts": []interface{}{map[string]string{"type": node.Type, "id": id, "name": node.Name, "etag": "0"}}},
+					})
+					return
+				}
+			}
+			id := fmt.Sprintf("%d", 100+len(suite.Remote))
+			suite.Remote[id] = &syncNode{Type: "folder", Name: body.Name, Parent: body.Parent.ID}
+			res.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(res).Encode(FolderEntry{Type: "folder", ID: id, Name: body.Name, ETag: "0"})
+		case req.Method == http.MethodPost && strings.HasPrefix(req.URL.Path, "/api/2.0/files/") && strings.HasSuffix(req.URL.Path, "/content"):
+			reader, err := req.MultipartReader()
+			if !suite.Assert().Nilf(err, "Upload should be a multipart body. Error: %s", err) {
+				res.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var attributes struct {
+				Name   string `json:"name"`
+				Parent struct {
+					ID string `json:"id"`
+				} `json:"parent"`
+			}
+			part, err := reader.NextPart()
+			if !suite.Assert().Nilf(err, "Failed reading attributes. Error: %s", err) {
+				res.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewDecoder(part).Decode(&attributes)
+			part, err = reader.NextPart()
+			if !suite.Assert().Nilf(err, "Failed reading file. Error: %s", err) {
+				res.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(part)
+
+			id := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/api/2.0/files/"), "/content")
+			if id == "content" { // new file
+				id = fmt.Sprintf("%d", 100+len(suite.Remote))
+				suite.Remote[id] = &syncNode{Type: "file", Name: attributes.Name, Parent: attributes.Parent.ID}
+			}
+			node, found := suite.Remote[id]
+			if !found {
+				res.WriteHeader(http.StatusNotFound)
+				return
+			}
+			for _, refused := range suite.Refused {
+				if refused == node.Name {
+					delete(suite.Remote, id)
+					res.WriteHeader(http.StatusForbidden)
+					payload, _ := json.Marshal(Forbidden)
+					_, _ = res.Write(payload)
+					return
+				}
+			}
+			node.Data = data
+			res.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(res).Encode(FileCollection{Count: 1, Files: []FileEntry{{
+				Type:     "file",
+				ID:       id,
+				Name:     node.Name,
+				Size:     int64(len(data)),
+				Checksum: node.Checksum(),
+			}}})
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// CreateDirectory creates a local directory with the given files, indexed by their relative path
+func (suite *UploadDirectorySuite) CreateDirectory(files map[string]string) string {
+	directory := suite.T().TempDir()
+	for relative, content := range files {
+		localPath := filepath.Join(directory, filepath.FromSlash(relative))
+		suite.Require().Nil(os.MkdirAll(filepath.Dir(localPath), 0755))
+		suite.Require().Nil(os.WriteFile(localPath, []byte(content), 0644))
+	}
+	return directory
+}
+
+// Statuses gives the status of each file of a report, indexed by their path
+func (suite *UploadDirectorySuite) Statuses(report *UploadReport) map[string]string {
+	statuses := map[string]string{}
+	for _, result := range report.Files {
+		statuses[result.Path] = result.Status
+	}
+	return statuses
+}
+
+// Find gives the remote node with the given name in the given folder
+func (suite *UploadDirectorySuite) Find(parent, name string) *syncNode {
+	suite.Mutex.Lock()
+	defer suite.Mutex.Unlock()
+	for _, node := range suite.Remote {
+		if node.Parent == parent && node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+func (suite *UploadDirectorySuite) TestCanUploadDirectory() {
+	directory := suite.CreateDirectory(map[string]string{
+		"hello.txt":       "Hello, World!",
+		"docs/report.pdf": "%PDF",
+		"new/notes.txt":   "Some notes",
+	})
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	report, err := client.Files.UploadDirectory(context.Background(), directory, &PathEntry{ID: "0"}, nil)
+	suite.Require().Nilf(err, "Failed uploading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"hello.txt": "skipped", "docs/report.pdf": "created", "new/notes.txt": "created"}, suite.Statuses(report))
+	suite.Assert().Empty(report.Failed())
+	suite.Require().NotNil(suite.Find("1", "report.pdf"), "docs/report.pdf should be uploaded in the existing folder")
+	suite.Assert().Equal([]byte("%PDF"), suite.Find("1", "report.pdf").Data)
+	suite.Assert().NotNil(suite.Find("0", "new"), "The new folder should be created")
+}
+
+func (suite *UploadDirectorySuite) TestCanUploadNewVersionOfChangedFiles() {
+	directory := suite.CreateDirectory(map[string]string{"hello.txt": "Bye, World!"})
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	report, err := client.Files.UploadDirectory(context.Background(), directory, &PathEntry{ID: "0"}, nil)
+	suite.Require().Nilf(err, "Failed uploading directory. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"hello.txt": "updated"}, suite.Statuses(report))
+	suite.Require().NotNil(report.Files[0].File)
+	suite.Assert().Equal("10", report.Files[0].File.ID, "The existing file should get a new version")
+	suite.Assert().Equal([]byte("Bye, World!"), suite.Find("0", "hello.txt").Data)
+}
+
+func (suite *UploadDirectorySuite) TestShouldReportFailedUploads() {
+	directory := suite.CreateDirectory(map[string]string{
+		"notes.txt":       "Some notes",
+		"secret.txt":      "Secret",
+		"docs/report.pdf": "%PDF",
+	})
+	suite.Mutex.Lock()
+	suite.Refused = []string{"secret.txt"}
+	suite.Mutex.Unlock()
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	report, err := client.Files.UploadDirectory(context.Background(), directory, &PathEntry{ID: "0"}, &UploadDirectoryOptions{Workers: 1})
+	suite.Require().Nilf(err, "A failed file should not fail the upload. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"notes.txt": "created", "secret.txt": "failed", "docs/report.pdf": "created"}, suite.Statuses(report))
+	failed := report.Failed()
+	suite.Require().Len(failed, 1)
+	suite.Assert().Equal("secret.txt", failed[0].Path)
+	suite.Assert().Nil(failed[0].File)
+	suite.Assert().Truef(errors.Is(failed[0].Error, Forbidden), "Error should be Forbidden, error: %+v", failed[0].Error)
+}
+
+func (suite *UploadDirectorySuite) TestShouldReportFileWhoseNameIsUsedByFolder() {
+	directory := suite.CreateDirectory(map[string]string{
+		"docs":      "Not a folder",
+		"notes.txt": "Some notes",
+	})
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	report, err := client.Files.UploadDirectory(context.Background(), directory, &PathEntry{ID: "0"}, nil)
+	suite.Require().Nilf(err, "A failed file should not fail the upload. Error: %s", err)
+	suite.Assert().Equal(map[string]string{"docs": "failed", "notes.txt": "created"}, suite.Statuses(report))
+	failed := report.Failed()
+	suite.Require().Len(failed, 1)
+	suite.Assert().Truef(errors.Is(failed[0].Error, ItemNameInUse), "Error should be ItemNameInUse, error: %+v", failed[0].Error)
+}
@@ -0,0 +1,336 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/gildas/go-request"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type UploadSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Received  uploadReceived
+	// UpdateFails tells the fake server to refuse updating files
+	UpdateFails bool
+	// TooManyRequests is the number of uploads the fake server answers with 429 Too Many Requests
+	TooManyRequests int
+	// Attempts is the number of uploads the fake server received
+	Attempts int
+}
+
+// uploadReceived is what the fake server received with an upload
+type uploadReceived struct {
+	Path          string
+	IfMatch       string
+	Attributes    map[string]interface{}
+	Filename      string
+	ContentType   string
+	Data          []byte
+	Checksum      string
+	ContentLength int64
+}
+
+func TestUploadSuite(t *testing.T) {
+	suite.Run(t, new(UploadSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *UploadSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *UploadSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *UploadSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+	suite.Received = uploadReceived{}
+	suite.UpdateFails = false
+	suite.TooManyRequests = 0
+	suite.Attempts = 0
+}
+
+func (suite *UploadSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *UploadSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		if req.Method == http.MethodPut && req.URL.Path == "/2.0/files/1234" {
+			res.Header().Set("Content-Type", "application/json")
+			if suite.UpdateFails {
+				res.WriteHeader(http.StatusForbidden)
+				payload, _ := json.Marshal(Forbidden)
+				_, _ = res.Write(payload)
+				return
+			}
+			var update map[string]string
+			_ = json.NewDecoder(req.Body).Decode(&update)
+			payload, _ := json.Marshal(FileEntry{Type: "file", ID: "1234", Name: "data.txt", Description: update["description"]})
+			_, _ = res.Write(payload)
+			return
+		}
+		if req.Method != http.MethodPost || (req.URL.Path != "/api/2.0/files/content" && req.URL.Path != "/api/2.0/files/1234/content") {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		suite.Attempts++
+		if suite.TooManyRequests > 0 {
+			suite.TooManyRequests--
+			_, _ = io.Copy(io.Discard, req.Body)
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		reader, err := req.MultipartReader()
+		if !suite.Assert().Nilf(err, "Upload should be a multipart body. Error: %s", err) {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		attributes, err := reader.NextPart()
+		if !suite.Assert().Nilf(err, "Failed reading attributes. Error: %s", err) || !suite.Assert().Equal("attributes", attributes.FormName(), "The attributes should come first") {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(attributes).Decode(&suite.Received.Attributes)
+		file, err := reader.NextPart()
+		if !suite.Assert().Nilf(err, "Failed reading file. Error: %s", err) {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		suite.Received.Path = req.URL.Path
+		suite.Received.IfMatch = req.Header.Get("If-Match")
+		suite.Received.Filename = file.FileName()
+		suite.Received.ContentType = file.Header.Get("Content-Type")
+		suite.Received.Data, _ = io.ReadAll(file)
+		suite.Received.Checksum = req.Header.Get("Content-MD5")
+		suite.Received.ContentLength = req.ContentLength
+
+		res.Header().Set("Content-Type", "application/json")
+		checksum := sha1.Sum(suite.Received.Data)
+		if len(suite.Received.Checksum) > 0 && suite.Received.Checksum != hex.EncodeToString(checksum[:]) {
+			res.WriteHeader(http.StatusBadRequest)
+			payload, _ := json.Marshal(BadDigest)
+			_, _ = res.Write(payload)
+			return
+		}
+		res.WriteHeader(http.StatusCreated)
+		payload, _ := json.Marshal(FileCollection{Count: 1, Files: []FileEntry{{
+			Type:     "file",
+			ID:       "1234",
+			Name:     file.FileName(),
+			Size:     int64(len(suite.Received.Data)),
+			Checksum: hex.EncodeToString(checksum[:]),
+		}}})
+		_, _ = res.Write(payload)
+	}))
+}
+
+func (suite *UploadSuite) TestCanStreamUploadWithChecksum() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	reports := []Progress{}
+	progressWriter := &bytes.Buffer{}
+	results := FileCollection{}
+	err := client.Files.sendUpload(context.Background(), uploadURL, nil, uploadAttributes{Name: "data.txt", Parent: PathEntry{ID: "0"}}, "data.txt", &UploadOptions{
+		Filename:       "data.txt",
+		Reader:         bytes.NewReader(data),
+		ProgressWriter: progressWriter,
+		OnProgress:     func(progress Progress) { reports = append(reports, progress) },
+	}, &results)
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Require().Len(results.Files, 1)
+	suite.Assert().Equal("data.txt", suite.Received.Filename)
+	suite.Assert().Equal("data.txt", suite.Received.Attributes["name"])
+	suite.Assert().Equal("text/plain; charset=utf-8", suite.Received.ContentType)
+	suite.Assert().Equal(data, suite.Received.Data)
+	suite.Assert().Equal(results.Files[0].Checksum, suite.Received.Checksum)
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	last := reports[len(reports)-1]
+	suite.Assert().True(last.Done, "The last report should be done")
+	suite.Assert().Equal(int64(len(data)), last.Total, "The progress should only count the file content")
+	suite.Assert().Equal(int64(len(data)), last.Transferred)
+	suite.Assert().Equal(data, progressWriter.Bytes(), "The progress writer should only receive the file content")
+}
+
+func (suite *UploadSuite) TestCanStreamUploadWithoutChecksum() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	results := FileCollection{}
+	err := client.Files.sendUpload(context.Background(), uploadURL, nil, uploadAttributes{Name: "data.bin", Parent: PathEntry{ID: "0"}}, "data.bin", &UploadOptions{
+		Filename: "data.bin",
+		Reader:   io.MultiReader(strings.NewReader("Hello, "), strings.NewReader("World!")),
+	}, &results)
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Assert().Equal("Hello, World!", string(suite.Received.Data))
+	suite.Assert().Empty(suite.Received.Checksum, "No checksum can be computed for a reader that cannot seek")
+	suite.Assert().Equal(int64(-1), suite.Received.ContentLength, "The upload should be chunked")
+	suite.Assert().Equal("application/octet-stream", suite.Received.ContentType)
+}
+
+func (suite *UploadSuite) TestCanRetryUploadWhenRateLimited() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	suite.TooManyRequests = 1
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	reports := []Progress{}
+	results := FileCollection{}
+	err := client.Files.sendUpload(context.Background(), uploadURL, nil, uploadAttributes{Name: "data.txt", Parent: PathEntry{ID: "0"}}, "data.txt", &UploadOptions{
+		Filename:   "data.txt",
+		Reader:     bytes.NewReader(data),
+		OnProgress: func(progress Progress) { reports = append(reports, progress) },
+	}, &results)
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Assert().Equal(2, suite.Attempts, "The upload should have been sent again")
+	suite.Assert().Equal(data, suite.Received.Data)
+	suite.Assert().Equal(results.Files[0].Checksum, suite.Received.Checksum)
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	last := reports[len(reports)-1]
+	suite.Assert().Equal(int64(len(data)), last.Transferred, "The first attempt should not be counted")
+}
+
+func (suite *UploadSuite) TestShouldNotRetryUploadThatCannotSeek() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	suite.TooManyRequests = 1
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	err := client.Files.sendUpload(context.Background(), uploadURL, nil, uploadAttributes{Name: "data.bin", Parent: PathEntry{ID: "0"}}, "data.bin", &UploadOptions{
+		Filename: "data.bin",
+		Reader:   io.MultiReader(strings.NewReader("Hello, World!")),
+	}, nil)
+	suite.Require().NotNil(err, "Should have failed uploading")
+	suite.Assert().Truef(errors.Is(err, errors.HTTPStatusTooManyRequests), "Error should be a TooManyRequests. Error: %v", err)
+	suite.Assert().Equal(1, suite.Attempts, "A reader that cannot seek cannot be sent again")
+}
+
+func (suite *UploadSuite) TestCanUploadContent() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	results := FileCollection{}
+	err := client.Files.sendUpload(context.Background(), uploadURL, nil, uploadAttributes{Name: "hello.json", Parent: PathEntry{ID: "0"}}, "hello.json", &UploadOptions{
+		Filename: "hello.json",
+		Content:  request.ContentWithData([]byte(`{"hello":"world"}`), "application/json"),
+	}, &results)
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Assert().Equal(`{"hello":"world"}`, string(suite.Received.Data))
+	suite.Assert().Equal("application/json", suite.Received.ContentType)
+	suite.Assert().Equal(results.Files[0].Checksum, suite.Received.Checksum)
+}
+
+func (suite *UploadSuite) TestShouldFailUploadingWithBadDigest() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	reports := []Progress{}
+	uploadURL, _ := suite.ServerURL.Parse("/api/2.0/files/content")
+	err := client.Files.sendUpload(context.Background(), uploadURL, map[string]string{"Content-MD5": "bogus"}, uploadAttributes{Name: "data.txt", Parent: PathEntry{ID: "0"}}, "data.txt", &UploadOptions{
+		Filename:   "data.txt",
+		Reader:     io.MultiReader(strings.NewReader("Hello, World!")),
+		OnProgress: func(progress Progress) { reports = append(reports, progress) },
+	}, nil)
+	suite.Require().NotNil(err, "Should have failed uploading")
+	suite.Assert().Truef(errors.Is(err, BadDigest), "Error should be a BadDigest. Error: %v", err)
+	suite.Require().NotEmpty(reports, "Progress should have been reported")
+	suite.Assert().False(reports[len(reports)-1].Done, "A failed upload should not be reported as done")
+}
+
+func (suite *UploadSuite) TestCanUploadWithDescription() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	collection, err := client.Files.Upload(context.Background(), &UploadOptions{
+		Filename:    "data.txt",
+		Description: "Some data",
+		Content:     request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Require().Len(collection.Files, 1)
+	suite.Assert().Equal("Some data", collection.Files[0].Description)
+	suite.Assert().Equal("0", suite.Received.Attributes["parent"].(map[string]interface{})["id"])
+}
+
+func (suite *UploadSuite) TestShouldGetUploadedFilesWhenDescriptionFails() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	suite.UpdateFails = true
+	collection, err := client.Files.Upload(context.Background(), &UploadOptions{
+		Filename:    "data.txt",
+		Description: "Some data",
+		Content:     request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().NotNil(err, "Should have failed setting the description")
+	suite.Assert().Truef(errors.Is(err, Forbidden), "Error should be Forbidden. Error: %v", err)
+	suite.Require().NotNil(collection, "The uploaded files should be returned")
+	suite.Require().Len(collection.Files, 1)
+	suite.Assert().Equal("1234", collection.Files[0].ID)
+}
+
+func (suite *UploadSuite) TestCanUploadNewVersion() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	collection, err := client.Files.Upload(context.Background(), &UploadOptions{
+		File:    &FileEntry{ID: "1234", Name: "data.txt", ETag: "1"},
+		Content: request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading. Error: %s", err)
+	suite.Require().Len(collection.Files, 1)
+	suite.Assert().Equal("/api/2.0/files/1234/content", suite.Received.Path)
+	suite.Assert().Equal("1", suite.Received.IfMatch)
+	suite.Assert().Equal("data.txt", suite.Received.Filename)
+	suite.Assert().NotContains(suite.Received.Attributes, "name", "The file should not be renamed")
+	suite.Assert().NotContains(suite.Received.Attributes, "parent", "The file should not be moved")
+}
+
+func (suite *UploadSuite) TestShouldFailUploadingNewVersionWithoutFileID() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.Files.Upload(context.Background(), &UploadOptions{
+		File:    &FileEntry{Name: "data.txt"},
+		Content: request.ContentWithData([]byte("Hello, World!"), "text/plain"),
+	})
+	suite.Require().NotNil(err, "Should have failed uploading")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", err)
+}
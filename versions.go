@@ -0,0 +1,239 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// FileVersions module
+type FileVersions struct {
+	*Client
+	api *url.URL
+}
+
+// FileVersionCollection represents a collection of FileVersion
+type FileVersionCollection struct {
+	Count    int           `json:"total_count"`
+	Offset   int           `json:"offset"`
+	Limit    int           `json:"limit"`
+	Versions []FileVersion `json:"entries"`
+}
+
+// VersionListOptions contains the options for listing the versions of a file
+type VersionListOptions struct {
+	// Offset is the position of the first version to list
+	Offset int
+	// Limit is the maximum number of versions to list (Box.com default: 1000)
+	Limit int
+}
+
+// DefaultVersionListLimit is the number of versions fetched per request by ListAll
+const DefaultVersionListLimit = 1000
+
+// List lists a page of the previous versions of a file
+//
+// The current version of the file (FileEntry.FileVersion) is not part of the list.
+func (module *FileVersions) List(ctx context.Context, entry *FileEntry, options *VersionListOptions) (*FileVersionCollection, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if options == nil {
+		options = &VersionListOptions{}
+	}
+	if options.Offset < 0 {
+		return nil, errors.ArgumentInvalid.With("offset", options.Offset)
+	}
+	if options.Limit < 0 {
+		return nil, errors.ArgumentInvalid.With("limit", options.Limit)
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	parameters := map[string]string{}
+	if options.Offset > 0 {
+		parameters["offset"] = strconv.Itoa(options.Offset)
+	}
+	if options.Limit > 0 {
+		parameters["limit"] = strconv.Itoa(options.Limit)
+	}
+	listURL, _ := module.api.Parse(entry.ID + "/versions")
+	result := FileVersionCollection{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL:        listURL,
+		Parameters: parameters,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAll lists all the previous versions of a file, fetching as many pages as needed
+func (module *FileVersions) ListAll(ctx context.Context, entry *FileEntry) ([]FileVersion, error) {
+	versions := []FileVersion{}
+	options := VersionListOptions{Limit: DefaultVersionListLimit}
+	for {
+		page, err := module.List(ctx, entry, &options)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, page.Versions...)
+		options.Offset += len(page.Versions)
+		if len(page.Versions) == 0 || options.Offset >= page.Count {
+			return versions, nil
+		}
+	}
+}
+
+// Get retrieves a version of a file by its id
+func (module *FileVersions) Get(ctx context.Context, entry *FileEntry, versionID string) (*FileVersion, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if len(versionID) == 0 {
+		return nil, errors.ArgumentMissing.With("version")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	getURL, _ := module.api.Parse(entry.ID + "/versions/" + versionID)
+	result := FileVersion{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{URL: getURL}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Promote makes a copy of a previous version of a file the new current version
+//
+// To undo the last upload of a file, promote the most recent of its previous versions.
+func (module *FileVersions) Promote(ctx context.Context, entry *FileEntry, versionID string) (*FileVersion, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if len(versionID) == 0 {
+		return nil, errors.ArgumentMissing.With("version")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	promoteURL, _ := module.api.Parse(entry.ID + "/versions/current")
+	result := FileVersion{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL: promoteURL,
+		Payload: struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}{"file_version", versionID},
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete moves a previous version of a file to the trash
+//
+// If entry.ETag is set, the version is only deleted if the file has not changed since, otherwise PreconditionFailed is returned.
+func (module *FileVersions) Delete(ctx context.Context, entry *FileEntry, versionID string) error {
+	if entry == nil || len(entry.ID) == 0 {
+		return errors.ArgumentMissing.With("entry")
+	}
+	if len(versionID) == 0 {
+		return errors.ArgumentMissing.With("version")
+	}
+	if !module.Client.IsAuthenticated() {
+		return errors.Unauthorized.WithStack()
+	}
+
+	deleteURL, _ := module.api.Parse(entry.ID + "/versions/" + versionID)
+	_, err := module.Client.sendRequest(ctx, &request.Options{
+		Method:  http.MethodDelete,
+		URL:     deleteURL,
+		Headers: ifMatch(entry.ETag),
+	}, nil)
+	return err
+}
+
+// Restore restores a previous version of a file from the trash
+func (module *FileVersions) Restore(ctx context.Context, entry *FileEntry, versionID string) (*FileVersion, error) {
+	if entry == nil || len(entry.ID) == 0 {
+		return nil, errors.ArgumentMissing.With("entry")
+	}
+	if len(versionID) == 0 {
+		return nil, errors.ArgumentMissing.With("version")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	restoreURL, _ := module.api.Parse(entry.ID + "/versions/" + versionID)
+	result := FileVersion{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		Method: http.MethodPut,
+		URL:    restoreURL,
+		Payload: struct {
+			TrashedAt *core.Time `json:"trashed_at"`
+		}{nil},
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// IsTrashed tells if this version is in the trash
+func (version FileVersion) IsTrashed() bool {
+	return !version.TrashedAt.IsZero()
+}
+
+// MarshalJSON marshals this into JSON
+func (version FileVersion) MarshalJSON() ([]byte, error) {
+	type surrogate FileVersion
+	data, err := json.Marshal(struct {
+		surrogate
+		CA *core.Time `json:"created_at,omitempty"`
+		MA *core.Time `json:"modified_at,omitempty"`
+		TA *core.Time `json:"trashed_at,omitempty"`
+		RA *core.Time `json:"restored_at,omitempty"`
+		PA *core.Time `json:"purged_at,omitempty"`
+	}{
+		surrogate: surrogate(version),
+		CA:        optionalTime(version.CreatedAt),
+		MA:        optionalTime(version.ModifiedAt),
+		TA:        optionalTime(version.TrashedAt),
+		RA:        optionalTime(version.RestoredAt),
+		PA:        optionalTime(version.PurgedAt),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON decodes JSON
+func (version *FileVersion) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate FileVersion
+	var inner struct {
+		surrogate
+		CA *core.Time `json:"created_at"`
+		MA *core.Time `json:"modified_at"`
+		TA *core.Time `json:"trashed_at"`
+		RA *core.Time `json:"restored_at"`
+		PA *core.Time `json:"purged_at"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	*version = FileVersion(inner.surrogate)
+	version.CreatedAt = timeOrZero(inner.CA)
+	version.ModifiedAt = timeOrZero(inner.MA)
+	version.TrashedAt = timeOrZero(inner.TA)
+	version.RestoredAt = timeOrZero(inner.RA)
+	version.PurgedAt = timeOrZero(inner.PA)
+	return
+}
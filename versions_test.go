@@ -0,0 +1,244 @@
+package box_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-box"
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/gildas/go-request"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type VersionSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Client *box.Client
+	Root   *box.FolderEntry
+}
+
+func TestVersionSuite(t *testing.T) {
+	suite.Run(t, new(VersionSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *VersionSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+}
+
+func (suite *VersionSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+		folder, err := suite.Client.Folders.FindByName(context.Background(), "unit-test")
+		if err == nil {
+			err := suite.Client.Folders.Delete(context.Background(), folder, true)
+			suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
+		}
+	}
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *VersionSuite) BeforeTest(suiteName, testName string) {
+	var err error
+
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+
+	if suite.Client == nil {
+		suite.Logger.Infof("Creating a new box.Client")
+		suite.Client = box.NewClient(suite.Logger.ToContext(context.Background()))
+	}
+	if !suite.Client.IsAuthenticated() {
+		err = suite.Client.Auth.Authenticate(context.Background(), suite.FetchCredentials())
+		suite.Require().Nil(err, "Failed to authenticate box.Client")
+	}
+
+	if suite.Root == nil {
+		suite.Root, err = suite.Client.Folders.FindByName(context.Background(), "unit-test")
+		if err != nil {
+			suite.Root, err = suite.Client.Folders.Create(context.Background(), &box.FolderEntry{
+				Name: "unit-test",
+			})
+		}
+		suite.Require().Nilf(err, "Failed creating root folder. Error: %s", err)
+	}
+}
+
+func (suite *VersionSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+	if suite.Root != nil {
+		if !suite.Client.IsAuthenticated() {
+			err := suite.Client.Auth.Authenticate(context.Background(), suite.FetchCredentials())
+			suite.Require().Nil(err, "Failed to authenticate box.Client")
+		}
+		err := suite.Client.Folders.Delete(context.Background(), suite.Root, true)
+		suite.Assert().Nilf(err, "Failed deleting root folder. Error: %s", err)
+		suite.Root = nil
+	}
+}
+
+// *****************************************************************************
+
+func (suite *VersionSuite) FetchCredentials() box.Credentials {
+	suite.Logger.Infof("Fetching credentials from environment")
+	var credentials box.Credentials
+
+	config := core.GetEnvAsString("BOX_CONFIG", "")
+	if len(config) > 0 {
+		suite.Logger.Debugf("Found BOX_CONFIG")
+		err := json.Unmarshal([]byte(config), &credentials)
+		suite.Require().Nil(err, "Failed to unmarshal BOX_CONFIG")
+	} else {
+		credentials = box.Credentials{
+			ClientID:     core.GetEnvAsString("BOX_CLIENTID", ""),
+			ClientSecret: core.GetEnvAsString("BOX_CLIENTSECRET", ""),
+			EnterpriseID: core.GetEnvAsString("BOX_ENTERPRISEID", ""),
+			AppAuth: box.AppAuth{
+				PublicKeyID: core.GetEnvAsString("BOX_PUBLICKEYID", ""),
+				PrivateKey:  core.GetEnvAsString("BOX_PRIVATEKEY", ""),
+				Passphrase:  core.GetEnvAsString("BOX_PASSPHRASE", ""),
+			},
+		}
+	}
+	return credentials
+}
+
+func (suite *VersionSuite) UploadVersions(contents ...string) *box.FileEntry {
+	collection, err := suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+		Parent:   suite.Root.AsPathEntry(),
+		Filename: "hello.txt",
+		Content:  request.ContentWithData([]byte(contents[0]), "text/plain"),
+	})
+	suite.Require().Nilf(err, "Failed uploading a file. Error: %s", err)
+	entry := collection.Files[0]
+	for _, content := range contents[1:] {
+		collection, err = suite.Client.Files.Upload(context.Background(), &box.UploadOptions{
+			File:    &entry,
+			Content: request.ContentWithData([]byte(content), "text/plain"),
+		})
+		suite.Require().Nilf(err, "Failed uploading a new version. Error: %s", err)
+		entry = collection.Files[0]
+	}
+	return &entry
+}
+
+func (suite *VersionSuite) TestCanListVersions() {
+	entry := suite.UploadVersions("Hello", "Hello, World", "Hello, World!")
+
+	versions, err := suite.Client.FileVersions.List(context.Background(), entry, &box.VersionListOptions{Limit: 1})
+	suite.Require().Nilf(err, "Failed listing versions. Error: %s", err)
+	suite.Assert().Equal(2, versions.Count)
+	suite.Assert().Len(versions.Versions, 1)
+
+	all, err := suite.Client.FileVersions.ListAll(context.Background(), entry)
+	suite.Require().Nilf(err, "Failed listing all versions. Error: %s", err)
+	suite.Require().Len(all, 2)
+	for _, version := range all {
+		suite.Assert().NotEqual(entry.FileVersion.ID, version.ID, "The current version should not be listed")
+	}
+}
+
+func (suite *VersionSuite) TestCanGetVersion() {
+	entry := suite.UploadVersions("Hello", "Hello, World!")
+
+	versions, err := suite.Client.FileVersions.ListAll(context.Background(), entry)
+	suite.Require().Nilf(err, "Failed listing versions. Error: %s", err)
+	suite.Require().Len(versions, 1)
+
+	version, err := suite.Client.FileVersions.Get(context.Background(), entry, versions[0].ID)
+	suite.Require().Nilf(err, "Failed getting a version. Error: %s", err)
+	suite.Assert().Equal(versions[0].ID, version.ID)
+	suite.Assert().Equal(int64(len("Hello")), version.Size)
+}
+
+func (suite *VersionSuite) TestCanPromoteVersion() {
+	entry := suite.UploadVersions("Hello", "Hello, World!")
+
+	versions, err := suite.Client.FileVersions.ListAll(context.Background(), entry)
+	suite.Require().Nilf(err, "Failed listing versions. Error: %s", err)
+	suite.Require().Len(versions, 1)
+
+	promoted, err := suite.Client.FileVersions.Promote(context.Background(), entry, versions[0].ID)
+	suite.Require().Nilf(err, "Failed promoting a version. Error: %s", err)
+	suite.Assert().Equal(versions[0].Checksum, promoted.Checksum)
+
+	current, err := suite.Client.Files.FindByID(context.Background(), entry.ID)
+	suite.Require().Nilf(err, "Failed finding the file. Error: %s", err)
+	suite.Assert().Equal(promoted.ID, current.FileVersion.ID)
+	suite.Assert().Equal(versions[0].Checksum, current.Checksum)
+}
+
+func (suite *VersionSuite) TestCanDeleteAndRestoreVersion() {
+	entry := suite.UploadVersions("Hello", "Hello, World!")
+
+	versions, err := suite.Client.FileVersions.ListAll(context.Background(), entry)
+	suite.Require().Nilf(err, "Failed listing versions. Error: %s", err)
+	suite.Require().Len(versions, 1)
+
+	err = suite.Client.FileVersions.Delete(context.Background(), entry, versions[0].ID)
+	suite.Require().Nilf(err, "Failed deleting a version. Error: %s", err)
+
+	deleted, err := suite.Client.FileVersions.Get(context.Background(), entry, versions[0].ID)
+	suite.Require().Nilf(err, "Failed getting a version. Error: %s", err)
+	suite.Assert().True(deleted.IsTrashed(), "Version should be trashed")
+
+	restored, err := suite.Client.FileVersions.Restore(context.Background(), entry, versions[0].ID)
+	suite.Require().Nilf(err, "Failed restoring a version. Error: %s", err)
+	suite.Assert().False(restored.IsTrashed(), "Version should not be trashed anymore")
+}
+
+func (suite *VersionSuite) TestShouldFailListingVersionsWithMissingEntry() {
+	_, err := suite.Client.FileVersions.List(context.Background(), nil, nil)
+	suite.Require().NotNil(err, "Should have failed listing versions")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+}
+
+func (suite *VersionSuite) TestShouldFailGettingVersionWithMissingID() {
+	_, err := suite.Client.FileVersions.Get(context.Background(), &box.FileEntry{ID: "1234"}, "")
+	suite.Require().NotNil(err, "Should have failed getting version")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Errors should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("version", details.What)
+}
+
+func (suite *VersionSuite) TestCanUnmarshalFileVersion() {
+	payload := []byte(`{"type":"file_version","id":"5678","sha1":"abcd","name":"hello.txt","size":13,"uploader_display_name":"John","created_at":"2024-01-02T03:04:05Z","trashed_at":"2024-01-03T03:04:05Z","trashed_by":{"type":"user","id":"42"}}`)
+	var version box.FileVersion
+	err := json.Unmarshal(payload, &version)
+	suite.Require().Nilf(err, "Failed unmarshaling FileVersion. Error: %s", err)
+	suite.Assert().Equal("5678", version.ID)
+	suite.Assert().Equal(int64(13), version.Size)
+	suite.Assert().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), version.CreatedAt.UTC())
+	suite.Assert().True(version.IsTrashed())
+	suite.Require().NotNil(version.TrashedBy)
+	suite.Assert().Equal("42", version.TrashedBy.ID)
+}
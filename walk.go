@@ -0,0 +1,237 @@
+package box
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gildas/go-errors"
+)
+
+// WalkOptions contains the options for walking a folder tree
+type WalkOptions struct {
+	// Parallelism is the number of folders listed at the same time (default: 1)
+	//
+	// When Parallelism is more than 1, the WalkFunc is called from several goroutines.
+	Parallelism int
+	// MaxDepth is the maximum depth of the walk, 1 walks only the items of the root folder (default: 0, no limit)
+	MaxDepth int
+	// Fields are the fields to get for each item, they must contain "type", "id" and "name"
+	Fields []string
+}
+
+// WalkFunc is the function called by Folders.Walk for each item
+//
+// path is the path of the item relative to the root folder, the root folder itself is "/".
+//
+// If listing a folder fails, the function is called a second time for that folder with the error.
+// Returning a non nil error stops the walk and Folders.Walk returns that error, except:
+//   - fs.SkipDir skips the folder, or the remaining items of the parent folder if the item is a file,
+//   - fs.SkipAll stops the walk and Folders.Walk returns nil.
+type WalkFunc func(path string, item *Item, err error) error
+
+// WalkRateLimitAttempts is the number of times the listing of a folder is tried when Box.com limits the request rate
+const WalkRateLimitAttempts = 5
+
+// walker walks a folder tree
+type walker struct {
+	module  *Folders
+	ctx     context.Context
+	cancel  context.CancelFunc
+	fn      WalkFunc
+	options WalkOptions
+	slots   chan struct{}
+	wait    sync.WaitGroup
+	mutex   sync.Mutex
+	err     error
+	backoff time.Duration
+	paused  time.Time
+}
+
+// Walk walks the folder tree of root, calling fn for root and each of its files, folders and web links
+//
+// Walk is modelled after filepath.WalkDir, the items of a folder are given in the order of Box.com.
+// With a Parallelism of 1, the tree is walked depth first, otherwise subfolders are walked concurrently.
+//
+// When Box.com limits the request rate, all the listings of the walk are paused before the failed listing is tried again,
+// up to WalkRateLimitAttempts times.
+func (module *Folders) Walk(ctx context.Context, root *FolderEntry, fn WalkFunc, options *WalkOptions) error {
+	if root == nil || len(root.ID) == 0 {
+		return errors.ArgumentMissing.With("root")
+	}
+	if fn == nil {
+		return errors.ArgumentMissing.With("fn")
+	}
+	if !module.Client.IsAuthenticated() {
+		return errors.Unauthorized.WithStack()
+	}
+
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	walker := &walker{module: module, ctx: walkCtx, cancel: cancel, fn: fn}
+	if options != nil {
+		walker.options = *options
+	}
+	if walker.options.Parallelism < 1 {
+		walker.options.Parallelism = 1
+	}
+	// The calling goroutine walks too, so it does not need a slot
+	walker.slots = make(chan struct{}, walker.options.Parallelism-1)
+
+	rootItem := &Item{Type: "folder", Folder: root}
+	if err := fn("/", rootItem, nil); err != nil {
+		if err == fs.SkipDir || err == fs.SkipAll {
+			return nil
+		}
+		return err
+	}
+	walker.walk("/", rootItem, 1)
+	walker.wait.Wait()
+
+	if walker.err != nil {
+		if walker.err == fs.SkipAll {
+			return nil
+		}
+		return walker.err
+	}
+	if ctx.Err() != nil {
+		return errors.WithStack(ctx.Err())
+	}
+	return nil
+}
+
+// walk walks the items of a folder, depth is the depth of these items
+func (walker *walker) walk(folderPath string, folder *Item, depth int) {
+	items, err := walker.list(folder.ID())
+	if err != nil {
+		if walker.ctx.Err() != nil {
+			return
+		}
+		if err = walker.fn(folderPath, folder, err); err != nil && err != fs.SkipDir {
+			walker.stop(err)
+		}
+		return
+	}
+	for index := range items {
+		if walker.ctx.Err() != nil {
+			return
+		}
+		item := &items[index]
+		itemPath := strings.TrimSuffix(folderPath, "/") + "/" + item.Name()
+		if err := walker.fn(itemPath, item, nil); err != nil {
+			if err == fs.SkipDir {
+				if item.Folder != nil {
+					continue
+				}
+				return
+			}
+			walker.stop(err)
+			return
+		}
+		if item.Folder != nil && (walker.options.MaxDepth == 0 || depth < walker.options.MaxDepth) {
+			walker.descend(itemPath, item, depth+1)
+		}
+	}
+}
+
+// descend walks a subfolder in a new goroutine if the parallelism allows it, otherwise in the current goroutine
+func (walker *walker) descend(folderPath string, folder *Item, depth int) {
+	select {
+	case walker.slots <- struct{}{}:
+		walker.wait.Add(1)
+		go func() {
+			defer func() {
+				<-walker.slots
+				walker.wait.Done()
+			}()
+			walker.walk(folderPath, folder, depth)
+		}()
+	default:
+		walker.walk(folderPath, folder, depth)
+	}
+}
+
+// list lists all the items of a folder, slowing down the walk when Box.com limits the request rate
+func (walker *walker) list(folderID string) ([]Item, error) {
+	for attempt := 1; ; attempt++ {
+		if err := walker.pause(); err != nil {
+			return nil, err
+		}
+		items := walker.module.Items(walker.ctx, &PathEntry{Type: "folder", ID: folderID}, &ItemsOptions{Fields: walker.options.Fields})
+		items.attempts = 1 // the walker retries itself, so a rate limited request pauses the whole walk
+		list := []Item{}
+		for items.Next() {
+			list = append(list, *items.Item())
+		}
+		err := items.Err()
+		if err == nil {
+			walker.speedUp()
+			return list, nil
+		}
+		if !isRateLimited(err) || attempt >= WalkRateLimitAttempts {
+			return nil, err
+		}
+		walker.slowDown()
+	}
+}
+
+// pause waits until the walk is not paused anymore
+func (walker *walker) pause() error {
+	walker.mutex.Lock()
+	delay := time.Until(walker.paused)
+	walker.mutex.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	walker.module.Client.Logger.Child(nil, "walk").Infof("Request rate limit exceeded, waiting for %s before trying again", delay)
+	select {
+	case <-walker.ctx.Done():
+		return errors.WithStack(walker.ctx.Err())
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// slowDown doubles the delay the walk is paused for after a rate limited request
+func (walker *walker) slowDown() {
+	walker.mutex.Lock()
+	defer walker.mutex.Unlock()
+	walker.backoff *= 2
+	if walker.backoff < DefaultRetryAfter {
+		walker.backoff = DefaultRetryAfter
+	}
+	if paused := time.Now().Add(walker.backoff); paused.After(walker.paused) {
+		walker.paused = paused
+	}
+}
+
+// speedUp halves the delay the walk is paused for after a successful request
+func (walker *walker) speedUp() {
+	walker.mutex.Lock()
+	defer walker.mutex.Unlock()
+	if walker.backoff /= 2; walker.backoff < DefaultRetryAfter {
+		walker.backoff = 0
+	}
+}
+
+// stop stops the walk with the given error, only the first error is kept
+func (walker *walker) stop(err error) {
+	walker.mutex.Lock()
+	defer walker.mutex.Unlock()
+	if walker.err == nil {
+		walker.err = err
+	}
+	walker.cancel()
+}
+
+// isRateLimited tells if the error means Box.com limited the request rate
+func isRateLimited(err error) bool {
+	var details *RequestError
+	if errors.As(err, &details) {
+		return details.StatusCode == http.StatusTooManyRequests
+	}
+	return errors.Is(err, errors.HTTPStatusTooManyRequests)
+}
@@ -0,0 +1,217 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type WalkSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server      *httptest.Server
+	ServerURL   *url.URL
+	Active      atomic.Int32
+	MaxActive   atomic.Int32
+	RateLimited atomic.Int32
+	Requests    atomic.Int32
+}
+
+func TestWalkSuite(t *testing.T) {
+	suite.Run(t, new(WalkSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *WalkSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *WalkSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *WalkSuite) BeforeTest(suiteName, testName string) {
+	suite.Active.Store(0)
+	suite.MaxActive.Store(0)
+	suite.RateLimited.Store(0)
+	suite.Requests.Store(0)
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *WalkSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+// walkTree is the folder tree served by the test server, folder "9" cannot be listed
+var walkTree = map[string][]map[string]string{
+	"0": {{"type": "folder", "id": "1", "name": "a"}, {"type": "folder", "id": "2", "name": "b"}, {"type": "file", "id": "10", "name": "root.txt"}},
+	"1": {{"type": "file", "id": "11", "name": "a1.txt"}, {"type": "file", "id": "12", "name": "a2.txt"}, {"type": "folder", "id": "3", "name": "c"}},
+	"2": {{"type": "file", "id": "13", "name": "b1.txt"}, {"type": "web_link", "id": "14", "name": "link"}},
+	"3": {{"type": "file", "id": "15", "name": "c1.txt"}},
+	"4": {{"type": "folder", "id": "9", "name": "locked"}, {"type": "file", "id": "16", "name": "d1.txt"}},
+}
+
+func (suite *WalkSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		suite.Requests.Add(1)
+		active := suite.Active.Add(1)
+		defer suite.Active.Add(-1)
+		for max := suite.MaxActive.Load(); active > max && !suite.MaxActive.CompareAndSwap(max, active); max = suite.MaxActive.Load() {
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if suite.RateLimited.Add(-1) >= 0 {
+			res.Header().Set("Content-Type", "application/json")
+			res.Header().Set("Retry-After", "0")
+			res.WriteHeader(http.StatusTooManyRequests)
+			_, _ = res.Write([]byte(`{"type":"error","status":429,"code":"rate_limit_exceeded","message":"Request rate limit exceeded"}`))
+			return
+		}
+		segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/2.0/"), "/")
+		if len(segments) != 3 || segments[0] != "folders" || segments[2] != "items" {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		entries, found := walkTree[segments[1]]
+		if !found {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(res).Encode(map[string]interface{}{"entries": entries, "limit": 1000, "next_marker": ""})
+	}))
+}
+
+func (suite *WalkSuite) Walk(client *Client, rootID string, options *WalkOptions, skip func(path string, item *Item) error) ([]string, error) {
+	var mutex sync.Mutex
+	paths := []string{}
+	err := client.Folders.Walk(context.Background(), &FolderEntry{ID: rootID}, func(path string, item *Item, err error) error {
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		paths = append(paths, path)
+		mutex.Unlock()
+		if skip != nil {
+			return skip(path, item)
+		}
+		return nil
+	}, options)
+	sort.Strings(paths)
+	return paths, err
+}
+
+func (suite *WalkSuite) TestCanWalk() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "0", nil, nil)
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Equal([]string{"/", "/a", "/a/a1.txt", "/a/a2.txt", "/a/c", "/a/c/c1.txt", "/b", "/b/b1.txt", "/b/link", "/root.txt"}, paths)
+	suite.Assert().Equal(int32(1), suite.MaxActive.Load(), "The folders should have been listed one at a time")
+}
+
+func (suite *WalkSuite) TestCanWalkInParallel() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "0", &WalkOptions{Parallelism: 4}, nil)
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Len(paths, 10)
+	suite.Assert().Greater(suite.MaxActive.Load(), int32(1), "The folders should have been listed concurrently")
+	suite.Assert().LessOrEqual(suite.MaxActive.Load(), int32(4))
+}
+
+func (suite *WalkSuite) TestCanWalkWithMaxDepth() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "0", &WalkOptions{MaxDepth: 1}, nil)
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Equal([]string{"/", "/a", "/b", "/root.txt"}, paths)
+}
+
+func (suite *WalkSuite) TestCanSkipDir() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "0", nil, func(path string, item *Item) error {
+		if path == "/a" || path == "/b/b1.txt" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Equal([]string{"/", "/a", "/b", "/b/b1.txt", "/root.txt"}, paths)
+}
+
+func (suite *WalkSuite) TestCanSkipAll() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "0", nil, func(path string, item *Item) error {
+		if path == "/a" {
+			return fs.SkipAll
+		}
+		return nil
+	})
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Equal([]string{"/", "/a"}, paths)
+}
+
+func (suite *WalkSuite) TestCanWalkWhenRateLimited() {
+	suite.RateLimited.Store(2)
+	start := time.Now()
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "3", nil, nil)
+	suite.Require().Nilf(err, "Failed walking. Error: %s", err)
+	suite.Assert().Equal([]string{"/", "/c1.txt"}, paths)
+	suite.Assert().Equal(int32(3), suite.Requests.Load(), "Each rate limited listing should have been sent once")
+	suite.Assert().GreaterOrEqual(time.Since(start), 3*DefaultRetryAfter, "The walk should have paused for 1s, then 2s")
+}
+
+func (suite *WalkSuite) TestShouldFailWalkingWithListingError() {
+	paths, err := suite.Walk(newTestClient(suite.T(), suite.ServerURL, suite.Logger), "4", nil, nil)
+	suite.Require().NotNil(err, "Should have failed walking")
+	suite.Assert().Truef(errors.Is(err, errors.NotFound), "Error should be a Not Found Error. Error: %v", err)
+	suite.Assert().Equal([]string{"/", "/locked"}, paths)
+}
+
+func (suite *WalkSuite) TestShouldFailWalkingWithMissingRoot() {
+	err := newTestClient(suite.T(), suite.ServerURL, suite.Logger).Folders.Walk(context.Background(), nil, func(string, *Item, error) error { return nil }, nil)
+	suite.Require().NotNil(err, "Should have failed walking")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", err)
+	var details *errors.Error
+	suite.Require().True(errors.As(err, &details), "Error should be an errors.Error")
+	suite.Assert().Equal("root", details.What)
+}
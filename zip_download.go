@@ -0,0 +1,191 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/gildas/go-core"
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-request"
+)
+
+// ZipDownloads module
+type ZipDownloads struct {
+	*Client
+	api *url.URL
+}
+
+// ZipDownload represents a zip archive of several files and folders that can be downloaded
+type ZipDownload struct {
+	DownloadURL   *url.URL            `json:"-"`
+	StatusURL     *url.URL            `json:"-"`
+	ExpiresAt     time.Time           `json:"-"`
+	NameConflicts [][]ZipNameConflict `json:"name_conflicts"`
+}
+
+// ZipNameConflict tells how an item was renamed in the archive as its name conflicted with other items
+type ZipNameConflict struct {
+	Type         string `json:"type"`
+	ID           string `json:"id"`
+	OriginalName string `json:"original_name"`
+	DownloadName string `json:"download_name"`
+}
+
+// ZipDownloadStatus gives the status of the download of a ZipDownload
+type ZipDownloadStatus struct {
+	State               string `json:"state"`
+	TotalFileCount      int    `json:"total_file_count"`
+	DownloadedFileCount int    `json:"downloaded_file_count"`
+	SkippedFileCount    int    `json:"skipped_file_count"`
+	SkippedFolderCount  int    `json:"skipped_folder_count"`
+}
+
+// ZipStatusPollInterval is the delay between 2 checks of the status of a ZipDownload in progress
+const ZipStatusPollInterval = 1 * time.Second
+
+// Create creates a zip archive of the given files and folders
+//
+// name is the name of the archive, without the .zip extension.
+// items can be given as *FileEntry, *FolderEntry or *PathEntry.
+// The archive must be downloaded before ZipDownload.ExpiresAt.
+func (module *ZipDownloads) Create(ctx context.Context, name string, items ...Entry) (*ZipDownload, error) {
+	if len(name) == 0 {
+		return nil, errors.ArgumentMissing.With("name")
+	}
+	if len(items) == 0 {
+		return nil, errors.ArgumentMissing.With("items")
+	}
+	type item struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	payload := struct {
+		Name  string `json:"download_file_name"`
+		Items []item `json:"items"`
+	}{Name: name, Items: make([]item, 0, len(items))}
+	for _, current := range items {
+		var entry *PathEntry
+		if current != nil {
+			entry = current.AsPathEntry()
+		}
+		if entry == nil || len(entry.ID) == 0 {
+			return nil, errors.ArgumentMissing.With("item")
+		}
+		if entry.Type != "file" && entry.Type != "folder" {
+			return nil, errors.ArgumentInvalid.With("item.type", entry.Type)
+		}
+		payload.Items = append(payload.Items, item{entry.Type, entry.ID})
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	result := ZipDownload{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{
+		URL:     module.api,
+		Payload: payload,
+	}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Download streams the zip archive to the given writer and returns the status of the download once Box.com has completed it
+//
+// The status tells how many files and folders were skipped (e.g.: because of their permissions).
+func (module *ZipDownloads) Download(ctx context.Context, archive *ZipDownload, writer io.Writer) (*ZipDownloadStatus, error) {
+	if archive == nil || archive.DownloadURL == nil {
+		return nil, errors.ArgumentMissing.With("archive")
+	}
+	if writer == nil {
+		return nil, errors.ArgumentMissing.With("writer")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	// The download URL is already authorized, the token must not be sent to its host
+	res, err := module.Client.sendContentRequest(ctx, archive.DownloadURL, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if _, err := io.Copy(writer, res.Body); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for {
+		status, err := module.Status(ctx, archive)
+		if err != nil {
+			return nil, err
+		}
+		if status.State != "in_progress" {
+			if status.State == "failed" {
+				return status, ZipDownloadFailed.With(archive.DownloadURL.String())
+			}
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, errors.WithStack(ctx.Err())
+		case <-time.After(ZipStatusPollInterval):
+		}
+	}
+}
+
+// Status gets the status of the download of the given zip archive
+//
+// The status is only available once the download has started.
+func (module *ZipDownloads) Status(ctx context.Context, archive *ZipDownload) (*ZipDownloadStatus, error) {
+	if archive == nil || archive.StatusURL == nil {
+		return nil, errors.ArgumentMissing.With("archive")
+	}
+	if !module.Client.IsAuthenticated() {
+		return nil, errors.Unauthorized.WithStack()
+	}
+
+	result := ZipDownloadStatus{}
+	if _, err := module.Client.sendRequest(ctx, &request.Options{URL: archive.StatusURL}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MarshalJSON marshals this into JSON
+func (archive ZipDownload) MarshalJSON() ([]byte, error) {
+	type surrogate ZipDownload
+	data, err := json.Marshal(struct {
+		surrogate
+		DU *core.URL  `json:"download_url"`
+		SU *core.URL  `json:"status_url"`
+		EA *core.Time `json:"expires_at,omitempty"`
+	}{
+		surrogate: surrogate(archive),
+		DU:        (*core.URL)(archive.DownloadURL),
+		SU:        (*core.URL)(archive.StatusURL),
+		EA:        optionalTime(archive.ExpiresAt),
+	})
+	return data, errors.JSONMarshalError.Wrap(err)
+}
+
+// UnmarshalJSON decodes JSON
+func (archive *ZipDownload) UnmarshalJSON(payload []byte) (err error) {
+	type surrogate ZipDownload
+	var inner struct {
+		surrogate
+		DU *core.URL  `json:"download_url"`
+		SU *core.URL  `json:"status_url"`
+		EA *core.Time `json:"expires_at"`
+	}
+	if err = json.Unmarshal(payload, &inner); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	*archive = ZipDownload(inner.surrogate)
+	archive.DownloadURL = (*url.URL)(inner.DU)
+	archive.StatusURL = (*url.URL)(inner.SU)
+	archive.ExpiresAt = timeOrZero(inner.EA)
+	return
+}
@@ -0,0 +1,159 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gildas/go-errors"
+	"github.com/gildas/go-logger"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/suite"
+)
+
+type ZipDownloadSuite struct {
+	suite.Suite
+	Name   string
+	Logger *logger.Logger
+	Start  time.Time
+
+	Server    *httptest.Server
+	ServerURL *url.URL
+	Statuses  atomic.Int32
+}
+
+func TestZipDownloadSuite(t *testing.T) {
+	suite.Run(t, new(ZipDownloadSuite))
+}
+
+// *****************************************************************************
+// Suite Tools
+
+func (suite *ZipDownloadSuite) SetupSuite() {
+	_ = godotenv.Load()
+	suite.Name = strings.TrimSuffix(reflect.TypeOf(suite).Elem().Name(), "Suite")
+	suite.Logger = logger.Create("test",
+		&logger.FileStream{
+			Path:         fmt.Sprintf("./log/test-%s.log", strings.ToLower(suite.Name)),
+			Unbuffered:   true,
+			SourceInfo:   true,
+			FilterLevels: logger.NewLevelSet(logger.TRACE),
+		},
+	).Child("test", "test")
+	suite.Logger.Infof("Suite Start: %s %s", suite.Name, strings.Repeat("=", 80-14-len(suite.Name)))
+	suite.Server = suite.CreateServer()
+	suite.ServerURL, _ = url.Parse(suite.Server.URL)
+}
+
+func (suite *ZipDownloadSuite) TearDownSuite() {
+	if suite.T().Failed() {
+		suite.Logger.Warnf("At least one test failed, we are not cleaning")
+		suite.T().Log("At least one test failed, we are not cleaning")
+	} else {
+		suite.Logger.Infof("All tests succeeded, we are cleaning")
+	}
+	suite.Server.Close()
+	suite.Logger.Infof("Suite End: %s %s", suite.Name, strings.Repeat("=", 80-12-len(suite.Name)))
+	suite.Logger.Close()
+}
+
+func (suite *ZipDownloadSuite) BeforeTest(suiteName, testName string) {
+	suite.Logger.Infof("Test Start: %s %s", testName, strings.Repeat("-", 80-13-len(testName)))
+	suite.Start = time.Now()
+}
+
+func (suite *ZipDownloadSuite) AfterTest(suiteName, testName string) {
+	duration := time.Since(suite.Start)
+	suite.Logger.Record("duration", duration.String()).Infof("Test End: %s %s", testName, strings.Repeat("-", 80-11-len(testName)))
+}
+
+// *****************************************************************************
+
+func (suite *ZipDownloadSuite) CreateServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/zip/1/content" {
+			suite.Assert().Empty(req.Header.Get("Authorization"), "The token should not be sent to the download URL")
+		} else {
+			suite.Assert().Equal("Bearer 1234", req.Header.Get("Authorization"))
+		}
+		server := "http://" + req.Host
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/2.0/zip_downloads":
+			var payload map[string]interface{}
+			suite.Require().Nil(json.NewDecoder(req.Body).Decode(&payload))
+			suite.Assert().Equal("archive", payload["download_file_name"])
+			suite.Assert().Equal([]interface{}{
+				map[string]interface{}{"type": "file", "id": "1234"},
+				map[string]interface{}{"type": "folder", "id": "5678"},
+			}, payload["items"])
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusAccepted)
+			fmt.Fprintf(res, `{"download_url":"%[1]s/zip/1/content","status_url":"%[1]s/2.0/zip_downloads/1/status","expires_at":"2024-01-02T03:04:05Z","name_conflicts":[[{"id":"1234","type":"file","original_name":"hello.txt","download_name":"hello-1.txt"}]]}`, server)
+		case req.Method == http.MethodGet && req.URL.Path == "/zip/1/content":
+			res.Header().Set("Content-Type", "application/zip")
+			_, _ = res.Write([]byte("PK\x03\x04"))
+		case req.Method == http.MethodGet && req.URL.Path == "/2.0/zip_downloads/1/status":
+			res.Header().Set("Content-Type", "application/json")
+			if suite.Statuses.Add(1) == 1 {
+				_, _ = res.Write([]byte(`{"state":"in_progress","total_file_count":3,"downloaded_file_count":1,"skipped_file_count":0,"skipped_folder_count":0}`))
+				return
+			}
+			_, _ = res.Write([]byte(`{"state":"succeeded","total_file_count":3,"downloaded_file_count":2,"skipped_file_count":1,"skipped_folder_count":0}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func (suite *ZipDownloadSuite) TestCanDownloadZip() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	archive, err := client.ZipDownloads.Create(context.Background(), "archive",
+		&FileEntry{ID: "1234", Name: "hello.txt"},
+		&FolderEntry{ID: "5678", Name: "folder"},
+	)
+	suite.Require().Nilf(err, "Failed creating zip download. Error: %s", err)
+	suite.Require().NotNil(archive.DownloadURL)
+	suite.Assert().Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), archive.ExpiresAt.UTC())
+	suite.Require().Len(archive.NameConflicts, 1)
+	suite.Assert().Equal("hello-1.txt", archive.NameConflicts[0][0].DownloadName)
+
+	suite.Statuses.Store(0)
+	writer := bytes.Buffer{}
+	status, err := client.ZipDownloads.Download(context.Background(), archive, &writer)
+	suite.Require().Nilf(err, "Failed downloading zip. Error: %s", err)
+	suite.Assert().Equal("PK\x03\x04", writer.String())
+	suite.Assert().Equal(int32(2), suite.Statuses.Load(), "The status should have been polled until completion")
+	suite.Assert().Equal("succeeded", status.State)
+	suite.Assert().Equal(1, status.SkippedFileCount)
+}
+
+func (suite *ZipDownloadSuite) TestShouldFailCreatingZipWithoutItems() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.ZipDownloads.Create(context.Background(), "archive")
+	suite.Require().NotNil(err, "Should have failed creating zip download")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", err)
+}
+
+func (suite *ZipDownloadSuite) TestShouldFailCreatingZipWithNilItem() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	var file *FileEntry
+	_, err := client.ZipDownloads.Create(context.Background(), "archive", file)
+	suite.Require().NotNil(err, "Should have failed creating zip download")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentMissing), "Error should be an Argument Missing Error. Error: %v", err)
+}
+
+func (suite *ZipDownloadSuite) TestShouldFailCreatingZipWithInvalidItem() {
+	client := newTestClient(suite.T(), suite.ServerURL, suite.Logger)
+	_, err := client.ZipDownloads.Create(context.Background(), "archive", &PathEntry{Type: "web_link", ID: "1234"})
+	suite.Require().NotNil(err, "Should have failed creating zip download")
+	suite.Assert().Truef(errors.Is(err, errors.ArgumentInvalid), "Error should be an Argument Invalid Error. Error: %v", err)
+}